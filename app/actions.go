@@ -0,0 +1,186 @@
+package app
+
+import (
+	"fmt"
+	"github.com/JLevconoks/k8ConsoleViewer/terminal"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// Action modes, copy is used when mode is not set.
+const (
+	ActionModeCopy   = "copy"
+	ActionModeLaunch = "launch"
+	ActionModeRun    = "run"
+)
+
+// actionItemTypes maps item type names used in config.json to item types.
+var actionItemTypes = map[string]Type{
+	"namespace": TypeNamespace,
+	"podGroup":  TypePodGroup,
+	"revision":  TypeRevision,
+	"pod":       TypePod,
+	"container": TypeContainer,
+}
+
+// Action is a user defined shortcut from config.json. Template is rendered with actionData of the selected item into
+// a command, which is copied to clipboard, opened in the terminal launcher or run inside the app, depending on mode.
+type Action struct {
+	Key      string `json:"key"`
+	Label    string `json:"label"`
+	Template string `json:"template"`
+	Mode     string `json:"mode,omitempty"`
+}
+
+// actionData are template fields, fields which don't apply to the item are empty. Commands which are launched or run
+// with sh get the fields quoted for the shell, so that names can't inject commands, Raw has them as they are.
+type actionData struct {
+	Context   string
+	Namespace string
+	PodGroup  string
+	Pod       string
+	Container string
+	Image     string
+	Kind      string
+	// Kubectl is kubectl command prefix targeting item's cluster, including --kubeconfig when it is set. It is quoted
+	// already.
+	Kubectl string
+	Raw     *actionData
+}
+
+// quoted returns data with fields quoted for the shell, empty fields stay empty.
+func (data actionData) quoted() actionData {
+	quote := func(value string) string {
+		if value == "" {
+			return ""
+		}
+		return terminal.ShellQuote(value)
+	}
+	return actionData{
+		Context:   quote(data.Context),
+		Namespace: quote(data.Namespace),
+		PodGroup:  quote(data.PodGroup),
+		Pod:       quote(data.Pod),
+		Container: quote(data.Container),
+		Image:     quote(data.Image),
+		Kind:      quote(data.Kind),
+		Kubectl:   data.Kubectl,
+		Raw:       data.Raw,
+	}
+}
+
+type action struct {
+	key      rune
+	label    string
+	mode     string
+	template *template.Template
+}
+
+// actionSet are parsed actions by item type.
+type actionSet map[Type][]action
+
+// newActionSet validates and parses actions from config.json.
+func newActionSet(config map[string][]Action) (actionSet, error) {
+	actions := make(actionSet)
+	for typeName, typeActions := range config {
+		itemType, ok := actionItemTypes[typeName]
+		if !ok {
+			return nil, fmt.Errorf("unknown action item type '%v', supported types: namespace, podGroup, revision, pod, container", typeName)
+		}
+		used := make(map[rune]string)
+		for _, a := range typeActions {
+			key, size := utf8.DecodeRuneInString(a.Key)
+			if a.Key == "" || size != len(a.Key) {
+				return nil, fmt.Errorf("action '%v' key has to be a single character, got '%v'", a.Label, a.Key)
+			}
+			if reserved := reservedKeys(); strings.ContainsRune(reserved, key) {
+				return nil, fmt.Errorf("action '%v' key '%c' is already used, reserved keys: %v", a.Label, key, reserved)
+			}
+			if label, ok := used[key]; ok {
+				return nil, fmt.Errorf("action '%v' key '%c' is already used by '%v' action of %v", a.Label, key, label, typeName)
+			}
+			used[key] = a.Label
+			mode := a.Mode
+			switch mode {
+			case "":
+				mode = ActionModeCopy
+			case ActionModeCopy, ActionModeLaunch, ActionModeRun:
+			default:
+				return nil, fmt.Errorf("action '%v' has unknown mode '%v', supported modes: copy, launch, run", a.Label, a.Mode)
+			}
+			tmpl, err := template.New(a.Label).Funcs(template.FuncMap{"quote": terminal.ShellQuote}).Parse(a.Template)
+			if err != nil {
+				return nil, fmt.Errorf("action '%v' has invalid template: %v", a.Label, err)
+			}
+			actions[itemType] = append(actions[itemType], action{key: key, label: a.Label, mode: mode, template: tmpl})
+		}
+	}
+	return actions, nil
+}
+
+// shortcuts returns built in shortcuts of the item together with its actions, an action replaces built in shortcut
+// with the same key.
+func (as actionSet) shortcuts(item Item) []shortcut {
+	result := make([]shortcut, 0)
+	typeActions := as[item.Type()]
+	for _, sc := range shortcuts(item) {
+		replaced := false
+		for _, a := range typeActions {
+			replaced = replaced || a.key == sc.key
+		}
+		if !replaced {
+			result = append(result, sc)
+		}
+	}
+	if len(typeActions) == 0 {
+		return result
+	}
+
+	data := newActionData(item)
+	for _, a := range typeActions {
+		var command strings.Builder
+		var err error
+		if a.mode == ActionModeCopy {
+			err = a.template.Execute(&command, data)
+		} else {
+			err = a.template.Execute(&command, data.quoted())
+		}
+		// Launched and run commands can change anything, so only copied ones are kept in read-only mode.
+		mutating := a.mode != ActionModeCopy
+		result = append(result, shortcut{key: a.key, label: a.label, command: command.String(), mode: a.mode, err: err, mutating: mutating})
+	}
+	return result
+}
+
+func newActionData(item Item) actionData {
+	data := actionData{}
+	var ns *Namespace
+	var pg *PodGroup
+	switch item.Type() {
+	case TypeNamespace:
+		ns = item.(*Namespace)
+	case TypePodGroup:
+		pg = item.(*PodGroup)
+	case TypeRevision:
+		pg = item.(*Revision).podGroup
+	case TypePod:
+		pod := item.(*Pod)
+		pg = pod.podGroup
+		data.Pod = pod.name
+	case TypeContainer:
+		cont := item.(*Container)
+		pg = cont.pod.podGroup
+		data.Pod, data.Container, data.Image = cont.pod.name, cont.name, cont.image
+	}
+	if pg != nil {
+		ns = pg.namespace
+		data.PodGroup, data.Kind = pg.name, pg.kind
+	}
+	if ns != nil {
+		data.Context, data.Namespace, data.Kubectl = ns.context, ns.name, ns.kubectl()
+	}
+	raw := data
+	data.Raw = &raw
+	return data
+}
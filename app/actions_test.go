@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestNewActionSetErrors(t *testing.T) {
+	testTable := []struct {
+		name   string
+		config map[string][]Action
+	}{
+		{"unknown type", map[string][]Action{"deployment": {{Key: "a", Label: "a", Template: "a"}}}},
+		{"empty key", map[string][]Action{"pod": {{Label: "a", Template: "a"}}}},
+		{"long key", map[string][]Action{"pod": {{Key: "ab", Label: "a", Template: "a"}}}},
+		{"reserved key", map[string][]Action{"pod": {{Key: "l", Label: "a", Template: "a"}}}},
+		{"duplicate key", map[string][]Action{"pod": {{Key: "a", Label: "a", Template: "a"}, {Key: "a", Label: "b", Template: "b"}}}},
+		{"unknown mode", map[string][]Action{"pod": {{Key: "a", Label: "a", Template: "a", Mode: "exec"}}}},
+		{"invalid template", map[string][]Action{"pod": {{Key: "a", Label: "a", Template: "{{.Pod"}}}},
+	}
+	for index, tc := range testTable {
+		t.Run(fmt.Sprintf("%v %v", index, tc.name), func(t *testing.T) {
+			if _, err := newActionSet(tc.config); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestNewActionSetMainKeys(t *testing.T) {
+	for _, mk := range mainKeys {
+		if _, err := newActionSet(map[string][]Action{"pod": {{Key: string(mk.key), Label: "a", Template: "a"}}}); err == nil {
+			t.Errorf("Key '%c' of the main frame should be reserved", mk.key)
+		}
+	}
+}
+
+func TestActionShortcuts(t *testing.T) {
+	actions, err := newActionSet(map[string][]Action{
+		"container": {
+			{Key: "i", Label: "image", Template: "{{.Image}}"},
+			{Key: "d", Label: "dive", Template: "dive {{.Image}}", Mode: ActionModeLaunch},
+			{Key: "q", Label: "quoted", Template: "echo {{quote .Raw.Image}} {{.Raw.Pod}}", Mode: ActionModeRun},
+			{Key: "1", Label: "tail logs", Template: "{{.Kubectl}} -n {{.Namespace}} logs {{.Pod}} -c {{.Container}} --tail=10", Mode: ActionModeRun},
+			{Key: "b", Label: "broken", Template: "{{.Replicas}}"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ns := &Namespace{name: "ns", context: "dev"}
+	pg := &PodGroup{name: "web", kind: KindDeployment, namespace: ns}
+	pod := &Pod{name: "web-1", podGroup: pg}
+	cont := &Container{name: "app", image: "registry/web:1.2 beta", pod: pod}
+
+	result := make([]string, 0)
+	for _, sc := range actions.shortcuts(cont) {
+		result = append(result, fmt.Sprintf("%c %v %v: %v %v", sc.key, sc.label, sc.mode, sc.command, sc.err != nil))
+	}
+	expected := []string{
+		"2 exec : kubectl --context dev -n ns exec -it web-1 -c app -- /bin/bash false",
+		"i image copy: registry/web:1.2 beta false",
+		"d dive launch: dive 'registry/web:1.2 beta' false",
+		"q quoted run: echo 'registry/web:1.2 beta' web-1 false",
+		"1 tail logs run: kubectl --context dev -n ns logs web-1 -c app --tail=10 false",
+		"b broken copy:  true",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Invalid shortcuts.\nWant: %q\nGot:  %q", expected, result)
+	}
+
+	help := shortcutHelp(cont, actions.shortcuts(cont), false)[:6]
+	expectedHelp := []string{"2 = exec", "i = image", "d = dive", "q = quoted", "1 = tail logs", "b = broken"}
+	if !reflect.DeepEqual(help, expectedHelp) {
+		t.Errorf("Invalid help.\nWant: %q\nGot:  %q", expectedHelp, help)
+	}
+	if len(actions.shortcuts(pod)) != len(shortcuts(pod)) {
+		t.Errorf("Container actions should not be added to pods")
+	}
+}
@@ -3,66 +3,149 @@ package app
 import (
 	"errors"
 	"fmt"
+	"github.com/JLevconoks/k8ConsoleViewer/clipboard"
+	"github.com/JLevconoks/k8ConsoleViewer/terminal"
 	"github.com/gdamore/tcell"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"log"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 )
 
 type Group struct {
-	Id       int       `json:"id"`
-	Name     string    `json:"name"`
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+	// GroupBy is a default list of pod grouping strategies for all NsGroups, see NsGroup.GroupBy.
+	GroupBy  []string  `json:"groupBy,omitempty"`
 	NsGroups []NsGroup `json:"nsGroups"`
 }
 
 type NsGroup struct {
-	Context    string   `json:"context"`
+	Context       string `json:"context"`
+	Kubeconfig    string `json:"kubeconfig,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"`
+	FieldSelector string `json:"fieldSelector,omitempty"`
+	// PodGroupLabels are label keys used to group pods which have no controller, in order of precedence.
+	PodGroupLabels []string `json:"podGroupLabels,omitempty"`
+	// GroupBy is an ordered list of pod grouping strategies, first one to match a pod wins. Supported strategies are
+	// 'owner', 'label:<key>', 'annotation:<key>' and 'regex:<pattern>'. Defaults to 'owner' and PodGroupLabels.
+	GroupBy    []string `json:"groupBy,omitempty"`
 	Namespaces []string `json:"namespaces"`
+
+	groupStrategies []groupStrategy
+}
+
+// Options are app wide settings which are coming from command line flags and config.json.
+type Options struct {
+	// Watch enables informer based pod caches instead of polling every PollInterval.
+	Watch bool
+	// Kubeconfig is an explicit kubeconfig path, used for every NsGroup which does not define its own.
+	// When empty, standard loading rules apply ($KUBECONFIG or ~/.kube/config).
+	Kubeconfig string
+	// LabelSelector and FieldSelector narrow down monitored pods, used for every NsGroup which does not define its own.
+	LabelSelector string
+	FieldSelector string
+	// PodGroupLabels and GroupBy are used for every NsGroup (and Group) which does not define its own.
+	PodGroupLabels []string
+	GroupBy        []string
+	// ReadOnly disables shortcuts and keys which change resources or exec into containers.
+	ReadOnly bool
+	// Config is read from config.json, flags which are set take precedence over it.
+	Config Config
 }
 
 type App struct {
-	k8Client K8Client
-	group    Group
+	k8Client  K8Client
+	group     Group
+	launcher  terminal.Launcher
+	clipboard *clipboard.Clipboard
+	actions   actionSet
+	guard     guard
 }
 
-func NewApp(context string, namespace string) (App, error) {
-	contextNameSet := make(map[string]struct{})
-	contextNameSet[context] = struct{}{}
-	k8Client, err := NewK8ClientSets(contextNameSet)
+func NewApp(context string, namespace string, options Options) (App, error) {
+	k8Client, err := NewK8ClientSets([]clusterKey{{kubeconfig: expandHome(options.Kubeconfig), context: context}})
 	if err != nil {
 		return App{}, err
 	}
+	if options.Watch {
+		k8Client.podCache = newPodCache()
+	}
 
 	var g Group
 	if strings.Contains(namespace, "*") {
-		g, err = buildGroupFromWildcard(k8Client, context, namespace)
+		g, err = buildGroupFromWildcard(k8Client, options.Kubeconfig, context, namespace)
 		if err != nil {
 			return App{}, err
 		}
 	} else {
-		g = buildGroup(fmt.Sprintf("%v/%v", context, namespace), context, namespace)
+		g = buildGroup(fmt.Sprintf("%v/%v", context, namespace), options.Kubeconfig, context, namespace)
+	}
+	if err := applyOptions(&g, options); err != nil {
+		return App{}, err
+	}
+	launcher, err := terminal.NewLauncher(options.Config.Launcher)
+	if err != nil {
+		return App{}, err
+	}
+	clip, err := clipboard.New(options.Config.Clipboard)
+	if err != nil {
+		return App{}, err
+	}
+	actions, err := newActionSet(options.Config.Actions)
+	if err != nil {
+		return App{}, err
 	}
 
 	return App{
-		k8Client: k8Client,
-		group:    g,
+		k8Client:  k8Client,
+		group:     g,
+		launcher:  launcher,
+		clipboard: clip,
+		actions:   actions,
+		guard:     newGuard(options.ReadOnly, g, options.Config),
 	}, nil
 }
 
-func NewAppFromGroup(group Group) (App, error) {
-	contextNameSet := make(map[string]struct{})
-	for i := range group.NsGroups {
-		contextNameSet[group.NsGroups[i].Context] = struct{}{}
+func NewAppFromGroup(group Group, options Options) (App, error) {
+	if err := applyOptions(&group, options); err != nil {
+		return App{}, err
+	}
+
+	clusters := make([]clusterKey, 0)
+	for _, nsGroup := range group.NsGroups {
+		clusters = append(clusters, clusterKey{kubeconfig: nsGroup.Kubeconfig, context: nsGroup.Context})
+	}
+	k8Client, err := NewK8ClientSets(clusters)
+	if err != nil {
+		return App{}, err
+	}
+	if options.Watch {
+		k8Client.podCache = newPodCache()
 	}
-	k8Client, err := NewK8ClientSets(contextNameSet)
+	launcher, err := terminal.NewLauncher(options.Config.Launcher)
+	if err != nil {
+		return App{}, err
+	}
+	clip, err := clipboard.New(options.Config.Clipboard)
+	if err != nil {
+		return App{}, err
+	}
+	actions, err := newActionSet(options.Config.Actions)
 	if err != nil {
 		return App{}, err
 	}
 	return App{
-		k8Client: k8Client,
-		group:    group,
+		k8Client:  k8Client,
+		group:     group,
+		launcher:  launcher,
+		clipboard: clip,
+		actions:   actions,
+		guard:     newGuard(options.ReadOnly, group, options.Config),
 	}, nil
 }
 
@@ -78,12 +161,17 @@ func (app *App) Run() {
 		os.Exit(1)
 	}
 
+	// tcell screen writes to the terminal with its lock held, copying with OSC 52 takes it as well.
+	if locker, ok := s.(sync.Locker); ok {
+		app.clipboard.SetTerminalLock(locker)
+	}
+
 	s.Clear()
-	gui := NewGui(s, app.group.Name)
+	gui := NewGui(s, app.group.Name, app.k8Client, app.launcher, app.clipboard, app.actions, app.guard)
 	gui.show(s)
 
 	quit := make(chan []string)
-	// Get namespace info loop.
+	// Get namespace info loop, it is driven either by the poll interval or by watch cache changes.
 	go func() {
 		for {
 			gui.statusBarCh <- "Updating namespace info..."
@@ -104,7 +192,7 @@ func (app *App) Run() {
 
 			gui.updateNamespaces(s, podListResults, endTime.Sub(startTime))
 
-			time.Sleep(5 * time.Second)
+			app.k8Client.waitForChanges()
 		}
 	}()
 
@@ -114,6 +202,31 @@ func (app *App) Run() {
 			ev := s.PollEvent()
 			switch ev := ev.(type) {
 			case *tcell.EventKey:
+				// Ctrl+C quits from inputs, popups and panes too, only the exec terminal passes it on to the shell.
+				if ev.Key() == tcell.KeyCtrlC && (gui.execFrame == nil || !gui.execFrame.visible) {
+					close(quit)
+					return
+				}
+				if gui.input != nil {
+					gui.handleInputKey(ev)
+					continue
+				}
+				if gui.popupFrame.visible {
+					gui.handlePopupKey(ev)
+					continue
+				}
+				if gui.logFrame != nil && gui.logFrame.visible {
+					gui.handleLogKey(ev)
+					continue
+				}
+				if gui.execFrame != nil && gui.execFrame.visible {
+					gui.handleExecKey(ev)
+					continue
+				}
+				if gui.runFrame != nil && gui.runFrame.visible {
+					gui.handleRunKey(ev)
+					continue
+				}
 				// This is to ignore event spam primarily from mouse scroll
 				if previousKeyEvent.Key() == ev.Key() && ev.When().Sub(previousKeyEvent.When()) < 5*time.Millisecond {
 					break
@@ -122,8 +235,8 @@ func (app *App) Run() {
 
 				switch ev.Key() {
 				case tcell.KeyEscape:
-					if gui.popupFrame.visible {
-						gui.hidePopupFrame()
+					if gui.mainFrame.filter != "" {
+						gui.clearFilter()
 						continue
 					}
 					fallthrough
@@ -152,20 +265,19 @@ func (app *App) Run() {
 					gui.getLogsFromPods()
 				case tcell.KeyCtrlK:
 					gui.getLogsAndFollowFromPods()
-				case tcell.KeyEnter:
-					gui.handleEnterKey()
-				}
-				switch ev.Rune() {
-				case 'c':
-					gui.handleCollapseAll()
-				case 'e':
-					gui.handleExpandAll()
-				default:
-					gui.handleRune(ev.Rune())
 				}
+				gui.handleMainRune(ev.Rune())
 
 			case *tcell.EventResize:
 				gui.handleResize()
+			case *tcell.EventInterrupt:
+				switch data := ev.Data().(type) {
+				case execEnded:
+					gui.handleExecEnded(data)
+				case func():
+					// Results of background work which has to update gui.
+					data()
+				}
 			}
 		}
 	}()
@@ -175,6 +287,7 @@ func (app *App) Run() {
 		exitMessages = s
 	}
 
+	app.k8Client.stop()
 	s.Fini()
 
 	log.SetOutput(os.Stdout)
@@ -183,27 +296,28 @@ func (app *App) Run() {
 	}
 }
 
-func buildGroup(groupName string, context string, namespace ...string) Group {
+func buildGroup(groupName string, kubeconfig string, context string, namespace ...string) Group {
 	return Group{
 		Id:   0,
 		Name: groupName,
 		NsGroups: []NsGroup{
 			{
 				Context:    context,
+				Kubeconfig: kubeconfig,
 				Namespaces: namespace,
 			},
 		},
 	}
 }
 
-func buildGroupFromWildcard(k8Client Client, context string, nsNameWC string) (Group, error) {
+func buildGroupFromWildcard(k8Client Client, kubeconfig string, context string, nsNameWC string) (Group, error) {
 	nsRegexString := strings.Replace(nsNameWC, "*", ".*", -1)
 	nsRegexString = fmt.Sprintf("^%v$", nsRegexString)
 	nsRegex, err := regexp.Compile(nsRegexString)
 	if err != nil {
 		return Group{}, err
 	}
-	nsList, err := k8Client.listNamespaces(context)
+	nsList, err := k8Client.listNamespaces(clusterKey{kubeconfig: expandHome(kubeconfig), context: context})
 	if err != nil {
 		return Group{}, err
 	}
@@ -218,5 +332,56 @@ func buildGroupFromWildcard(k8Client Client, context string, nsNameWC string) (G
 		return Group{}, errors.New(fmt.Sprintf("no namespaces found matching '%v'", nsNameWC))
 	}
 
-	return buildGroup(fmt.Sprintf("%v/%v", context, nsNameWC), context, nsNames...), nil
+	return buildGroup(fmt.Sprintf("%v/%v", context, nsNameWC), kubeconfig, context, nsNames...), nil
+}
+
+// applyOptions fills in NsGroup settings which are not defined in the group with values from options and validates them.
+func applyOptions(group *Group, options Options) error {
+	for i := range group.NsGroups {
+		nsGroup := &group.NsGroups[i]
+		if nsGroup.Kubeconfig == "" {
+			nsGroup.Kubeconfig = options.Kubeconfig
+		}
+		nsGroup.Kubeconfig = expandHome(nsGroup.Kubeconfig)
+		if nsGroup.LabelSelector == "" {
+			nsGroup.LabelSelector = options.LabelSelector
+		}
+		if nsGroup.FieldSelector == "" {
+			nsGroup.FieldSelector = options.FieldSelector
+		}
+		if err := validateSelectors(*nsGroup); err != nil {
+			return err
+		}
+
+		// Empty lists in groups.json disable label grouping or fall back to ungrouped pods, so only missing values are replaced.
+		if nsGroup.PodGroupLabels == nil {
+			nsGroup.PodGroupLabels = options.PodGroupLabels
+		}
+		groupBy := nsGroup.GroupBy
+		if groupBy == nil {
+			groupBy = group.GroupBy
+		}
+		if groupBy == nil {
+			groupBy = options.GroupBy
+		}
+		if groupBy == nil {
+			groupBy = defaultGroupBy(nsGroup.PodGroupLabels)
+		}
+		strategies, err := parseGroupStrategies(groupBy)
+		if err != nil {
+			return errors.New(fmt.Sprintf("invalid pod grouping for context '%v': %v", nsGroup.Context, err))
+		}
+		nsGroup.groupStrategies = strategies
+	}
+	return nil
+}
+
+func validateSelectors(nsGroup NsGroup) error {
+	if _, err := labels.Parse(nsGroup.LabelSelector); err != nil {
+		return fmt.Errorf("invalid label selector '%v' for context '%v': %v", nsGroup.LabelSelector, nsGroup.Context, err)
+	}
+	if _, err := fields.ParseSelector(nsGroup.FieldSelector); err != nil {
+		return fmt.Errorf("invalid field selector '%v' for context '%v': %v", nsGroup.FieldSelector, nsGroup.Context, err)
+	}
+	return nil
 }
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidateSelectors(t *testing.T) {
+	testTable := []struct {
+		name          string
+		labelSelector string
+		fieldSelector string
+		expectedErr   bool
+	}{
+		{"empty", "", "", false},
+		{"label equality", "team=payments,tier!=db", "", false},
+		{"label set", "env in (dev, staging),!canary", "", false},
+		{"field", "", "status.phase=Running,spec.nodeName!=node-1", false},
+		{"both", "app=web", "status.phase!=Succeeded", false},
+		{"label unbalanced set", "env in (dev", "", true},
+		{"label invalid value", "team=pay ments", "", true},
+		{"label invalid operator", "team>=1", "", true},
+		{"field without operator", "", "status.phase", true},
+		{"field set", "", "status.phase in (Running)", true},
+	}
+	for index, tc := range testTable {
+		t.Run(fmt.Sprintf("%v %v", index, tc.name), func(t *testing.T) {
+			err := validateSelectors(NsGroup{Context: "dev", LabelSelector: tc.labelSelector, FieldSelector: tc.fieldSelector})
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("Invalid error: %v", err)
+			}
+		})
+	}
+}
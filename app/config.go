@@ -0,0 +1,20 @@
+package app
+
+// Config are user settings from config.json, which is next to groups.json in the app directory.
+type Config struct {
+	// Launcher is a terminal launcher backend used by Ctrl shortcuts: auto, iterm2, tmux, kitty, wezterm or generic.
+	Launcher string `json:"launcher,omitempty"`
+	// Clipboard is a backend used by number shortcuts: auto, wl-copy, xclip, xsel, pbcopy or osc52.
+	Clipboard string `json:"clipboard,omitempty"`
+	// Actions are user defined shortcuts by item type: namespace, podGroup, revision, pod or container.
+	Actions map[string][]Action `json:"actions,omitempty"`
+	// Contexts are settings of individual contexts by context name.
+	Contexts map[string]ContextConfig `json:"contexts,omitempty"`
+}
+
+// ContextConfig are settings of a single context.
+type ContextConfig struct {
+	// Protected contexts are highlighted in the header and changes in them need extra confirmation. When it is not
+	// set, contexts with a "prod" or "production" word in the name are protected.
+	Protected *bool `json:"protected,omitempty"`
+}
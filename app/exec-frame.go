@@ -0,0 +1,271 @@
+package app
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/remotecommand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultExecShell = "/bin/bash"
+	execInputBuffer  = 64
+)
+
+// execEnded is posted as tcell interrupt event data when a session of the exec frame ends.
+type execEnded struct {
+	frame *ExecFrame
+	err   error
+}
+
+// terminalSizeQueue passes exec frame size changes to the remote terminal.
+type terminalSizeQueue chan remotecommand.TerminalSize
+
+func (q terminalSizeQueue) Next() *remotecommand.TerminalSize {
+	size, ok := <-q
+	if !ok {
+		return nil
+	}
+	return &size
+}
+
+// ExecFrame is a full screen terminal attached to a shell in a container, keys are sent to the shell except for
+// Ctrl+] which closes the frame.
+type ExecFrame struct {
+	sync.Mutex
+
+	width, height int
+	visible       bool
+	k8Client      K8Client
+	namespace     *Namespace
+	pod           string
+	container     string
+	command       []string
+	vt            *vtScreen
+	// input is written to the session in order by a single goroutine, so that the event loop is not blocked.
+	input    chan []byte
+	sizeCh   terminalSizeQueue
+	redrawCh chan struct{}
+	// stopCh ends the session when the frame is closed.
+	stopCh chan struct{}
+}
+
+func NewExecFrame(s tcell.Screen, k8Client K8Client, ns *Namespace, pod, container string, command []string) *ExecFrame {
+	width, height := s.Size()
+	ef := &ExecFrame{
+		width:     width,
+		height:    height,
+		k8Client:  k8Client,
+		namespace: ns,
+		pod:       pod,
+		container: container,
+		command:   command,
+		input:     make(chan []byte, execInputBuffer),
+		sizeCh:    make(terminalSizeQueue, 1),
+		redrawCh:  make(chan struct{}, 1),
+		stopCh:    make(chan struct{}),
+	}
+	ef.vt = newVTScreen(ef.terminalSize())
+	return ef
+}
+
+// open shows the frame and starts the session, execEnded event is posted to the screen when the session ends.
+func (ef *ExecFrame) open(s tcell.Screen) {
+	ef.visible = true
+	go func() {
+		for range ef.redrawCh {
+			ef.draw(s)
+			s.Show()
+			time.Sleep(logRedrawDelay)
+		}
+	}()
+
+	stdin, stdinWriter := io.Pipe()
+	go func() {
+		for data := range ef.input {
+			// Writes fail once the session ended, remaining input is dropped.
+			_, _ = stdinWriter.Write(data)
+		}
+		_ = stdinWriter.Close()
+	}()
+	ef.sendSize()
+	options := &v1.PodExecOptions{
+		Container: ef.container,
+		Command:   ef.command,
+		Stdin:     true,
+		Stdout:    true,
+		TTY:       true,
+	}
+	streams := remotecommand.StreamOptions{
+		Stdin:             stdin,
+		Stdout:            ef,
+		Tty:               true,
+		TerminalSizeQueue: ef.sizeCh,
+	}
+	go func() {
+		ns := ef.namespace
+		err := ef.k8Client.exec(ns.cluster(), ns.name, ef.pod, options, streams, ef.stopCh)
+		_ = stdin.Close()
+		_ = s.PostEvent(tcell.NewEventInterrupt(execEnded{frame: ef, err: err}))
+	}()
+	ef.requestRedraw()
+}
+
+// close ends the session by closing its connection, a shell would not exit on the end of input while a command in it
+// is running, e.g. top or tail -f.
+func (ef *ExecFrame) close() {
+	ef.Lock()
+	defer ef.Unlock()
+	if !ef.visible {
+		return
+	}
+	ef.visible = false
+	close(ef.stopCh)
+	close(ef.input)
+	close(ef.sizeCh)
+	close(ef.redrawCh)
+}
+
+// Write receives output of the remote terminal.
+func (ef *ExecFrame) Write(p []byte) (int, error) {
+	n, err := ef.vt.Write(p)
+	ef.requestRedraw()
+	return n, err
+}
+
+func (ef *ExecFrame) requestRedraw() {
+	ef.Lock()
+	defer ef.Unlock()
+	if !ef.visible {
+		return
+	}
+	select {
+	case ef.redrawCh <- struct{}{}:
+	default:
+	}
+}
+
+// terminalSize is the size of the remote terminal, first row is the title and last row is the status bar.
+func (ef *ExecFrame) terminalSize() (int, int) {
+	return ef.width, ef.height - 2
+}
+
+// sendSize replaces a size which was not read yet, so that the remote terminal gets only the latest one.
+func (ef *ExecFrame) sendSize() {
+	width, height := ef.terminalSize()
+	select {
+	case <-ef.sizeCh:
+	default:
+	}
+	ef.sizeCh <- remotecommand.TerminalSize{Width: uint16(width), Height: uint16(height)}
+}
+
+func (ef *ExecFrame) resize(s tcell.Screen) {
+	ef.Lock()
+	ef.width, ef.height = s.Size()
+	if ef.visible {
+		ef.vt.resize(ef.terminalSize())
+		ef.sendSize()
+	}
+	ef.Unlock()
+	s.Clear()
+	ef.requestRedraw()
+}
+
+// handleKey sends the key to the remote terminal.
+func (ef *ExecFrame) handleKey(ev *tcell.EventKey) {
+	data := keyBytes(ev)
+	if len(data) == 0 {
+		return
+	}
+	select {
+	case ef.input <- data:
+	default:
+		// Session does not read input, e.g. it is still connecting, so the key is dropped.
+	}
+}
+
+func (ef *ExecFrame) title() string {
+	return fmt.Sprintf("Exec: %v/%v -c %v | Ctrl+] = close", ef.namespace.name, ef.pod, ef.container)
+}
+
+func (ef *ExecFrame) draw(s tcell.Screen) {
+	ef.Lock()
+	defer ef.Unlock()
+	if !ef.visible {
+		return
+	}
+	drawS(s, ef.title(), 0, 0, ef.width, tcell.StyleDefault.Reverse(true))
+	ef.vt.draw(s, 0, 1)
+}
+
+// keyBytes translates a key to the input expected by xterm compatible terminals.
+func keyBytes(ev *tcell.EventKey) []byte {
+	var sequence string
+	switch ev.Key() {
+	case tcell.KeyRune:
+		data := []byte(string(ev.Rune()))
+		if ev.Modifiers()&tcell.ModAlt != 0 {
+			return append([]byte{0x1b}, data...)
+		}
+		return data
+	case tcell.KeyUp:
+		sequence = "\x1b[A"
+	case tcell.KeyDown:
+		sequence = "\x1b[B"
+	case tcell.KeyRight:
+		sequence = "\x1b[C"
+	case tcell.KeyLeft:
+		sequence = "\x1b[D"
+	case tcell.KeyHome:
+		sequence = "\x1b[H"
+	case tcell.KeyEnd:
+		sequence = "\x1b[F"
+	case tcell.KeyInsert:
+		sequence = "\x1b[2~"
+	case tcell.KeyDelete:
+		sequence = "\x1b[3~"
+	case tcell.KeyPgUp:
+		sequence = "\x1b[5~"
+	case tcell.KeyPgDn:
+		sequence = "\x1b[6~"
+	case tcell.KeyBacktab:
+		sequence = "\x1b[Z"
+	case tcell.KeyBackspace:
+		// Shells expect DEL for backspace, Ctrl+H is sent by some terminals for the same key.
+		sequence = "\x7f"
+	case tcell.KeyF1:
+		sequence = "\x1bOP"
+	case tcell.KeyF2:
+		sequence = "\x1bOQ"
+	case tcell.KeyF3:
+		sequence = "\x1bOR"
+	case tcell.KeyF4:
+		sequence = "\x1bOS"
+	case tcell.KeyF5:
+		sequence = "\x1b[15~"
+	case tcell.KeyF6:
+		sequence = "\x1b[17~"
+	case tcell.KeyF7:
+		sequence = "\x1b[18~"
+	case tcell.KeyF8:
+		sequence = "\x1b[19~"
+	case tcell.KeyF9:
+		sequence = "\x1b[20~"
+	case tcell.KeyF10:
+		sequence = "\x1b[21~"
+	case tcell.KeyF11:
+		sequence = "\x1b[23~"
+	case tcell.KeyF12:
+		sequence = "\x1b[24~"
+	default:
+		// Remaining keys are control characters, e.g. Enter, Tab, Esc and Ctrl+letter, which are sent as they are.
+		if ev.Key() < 0x80 {
+			return []byte{byte(ev.Key())}
+		}
+	}
+	return []byte(sequence)
+}
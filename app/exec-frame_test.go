@@ -0,0 +1,101 @@
+package app
+
+import (
+	"github.com/gdamore/tcell"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/remotecommand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeExecClient echoes input of exec sessions and records requested sizes and options.
+type fakeExecClient struct {
+	K8Client
+	optionsCh chan *v1.PodExecOptions
+	sizeCh    chan remotecommand.TerminalSize
+}
+
+func (c fakeExecClient) exec(cluster clusterKey, namespace, pod string, options *v1.PodExecOptions, streams remotecommand.StreamOptions, stopCh <-chan struct{}) error {
+	c.optionsCh <- options
+	go func() {
+		for size := streams.TerminalSizeQueue.Next(); size != nil; size = streams.TerminalSizeQueue.Next() {
+			c.sizeCh <- *size
+		}
+	}()
+	go func() {
+		_, _ = io.Copy(streams.Stdout, streams.Stdin)
+	}()
+	// Like a shell running a command, the session doesn't end with its input, only when it is stopped.
+	<-stopCh
+	return nil
+}
+
+func TestExecFrameSession(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	_ = screen.Init()
+	screen.SetSize(20, 6)
+	client := fakeExecClient{optionsCh: make(chan *v1.PodExecOptions, 1), sizeCh: make(chan remotecommand.TerminalSize, 2)}
+	ef := NewExecFrame(screen, client, &Namespace{name: "ns", context: "dev"}, "web-1", "app", []string{"/bin/sh"})
+	ef.open(screen)
+
+	options := <-client.optionsCh
+	if !options.TTY || !options.Stdin || options.Container != "app" || !reflect.DeepEqual(options.Command, []string{"/bin/sh"}) {
+		t.Errorf("Invalid exec options: %+v", options)
+	}
+	if size := <-client.sizeCh; size.Width != 20 || size.Height != 4 {
+		t.Errorf("Invalid initial size. Want: 20x4, Got: %vx%v", size.Width, size.Height)
+	}
+
+	for _, r := range "ls" {
+		ef.handleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	deadline := time.Now().Add(time.Second)
+	for ef.vt.text() != "ls" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if text := ef.vt.text(); text != "ls" {
+		t.Errorf("Input should be echoed. Want: %q, Got: %q", "ls", text)
+	}
+
+	screen.SetSize(30, 10)
+	ef.resize(screen)
+	if size := <-client.sizeCh; size.Width != 30 || size.Height != 8 {
+		t.Errorf("Invalid size after resize. Want: 30x8, Got: %vx%v", size.Width, size.Height)
+	}
+
+	ef.close()
+	deadline = time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if ev, ok := screen.PollEvent().(*tcell.EventInterrupt); ok {
+			if ended := ev.Data().(execEnded); ended.frame != ef || ended.err != nil {
+				t.Errorf("Invalid end of session: %+v", ended)
+			}
+			return
+		}
+	}
+	t.Errorf("Session should end when the frame is closed")
+}
+
+func TestKeyBytes(t *testing.T) {
+	testTable := []struct {
+		event    *tcell.EventKey
+		expected string
+	}{
+		{tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone), "a"},
+		{tcell.NewEventKey(tcell.KeyRune, 'ā', tcell.ModNone), "ā"},
+		{tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModAlt), "\x1bb"},
+		{tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), "\r"},
+		{tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModCtrl), "\x03"},
+		{tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone), "\x1b"},
+		{tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone), "\x7f"},
+		{tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone), "\x1b[A"},
+		{tcell.NewEventKey(tcell.KeyDelete, 0, tcell.ModNone), "\x1b[3~"},
+	}
+	for _, tc := range testTable {
+		if data := string(keyBytes(tc.event)); data != tc.expected {
+			t.Errorf("Invalid bytes for %v. Want: %q, Got: %q", tc.event.Name(), tc.expected, data)
+		}
+	}
+}
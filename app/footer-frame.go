@@ -3,14 +3,25 @@ package app
 import (
 	"github.com/gdamore/tcell"
 	"strings"
+	"time"
 )
 
+// toastDuration is how long a toast stays in the status bar, unless another message replaces it.
+const toastDuration = 5 * time.Second
+
+// toast is a highlighted status bar message with a result of an action, which is cleared after toastDuration.
+type toast struct {
+	value   string
+	isError bool
+}
+
 type FooterFrame struct {
 	x, y          int
 	width, height int
 	lines         []string
 	statusBar     *StringItem
 	statusBarCh   chan string
+	toastCh       chan toast
 }
 
 func NewFooterFrame(s tcell.Screen) *FooterFrame {
@@ -24,6 +35,7 @@ func NewFooterFrame(s tcell.Screen) *FooterFrame {
 		lines:       make([]string, FooterFrameHeight-1),
 		statusBar:   &StringItem{x: 0, y: winHeight - 1, length: 0, value: ""},
 		statusBarCh: sbCh,
+		toastCh:     make(chan toast),
 	}
 	frame.lines[0] = strings.Repeat("-", 25)
 	frame.listenForStatusMessages(s)
@@ -32,31 +44,33 @@ func NewFooterFrame(s tcell.Screen) *FooterFrame {
 
 func (ff *FooterFrame) listenForStatusMessages(s tcell.Screen) {
 	go func() {
-		for value := range ff.statusBarCh {
-			ff.statusBar.Update(s, value)
+		var clearCh <-chan time.Time
+		for {
+			select {
+			case value := <-ff.statusBarCh:
+				ff.statusBar.Update(s, value)
+				clearCh = nil
+			case t := <-ff.toastCh:
+				style := tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorGreen)
+				if t.isError {
+					style = style.Background(tcell.ColorRed)
+				}
+				value := " " + t.value + " "
+				ff.statusBar.Update(s, value)
+				drawRunes(s, value, ff.statusBar.x, ff.statusBar.y, ff.width, style)
+				clearCh = time.After(toastDuration)
+			case <-clearCh:
+				ff.statusBar.Update(s, "")
+				clearCh = nil
+			}
 			s.Show()
 		}
 	}()
 }
 
-func (ff *FooterFrame) updateShortcutInfo(s tcell.Screen, i Item) {
-	switch i.Type() {
-	case TypeNamespace:
-		ff.lines[1] = "1 = get all       3 = get events   5 = get secrets"
-		ff.lines[2] = "2 = get ingress   4 = describe     6 = get config map"
-	case TypePodGroup:
-		ff.lines[1] = "1 = describe      3 = scale   Ctrl+E = exec to all     Ctrl+K = follow logs from all"
-		ff.lines[2] = "2 = delete deploy             Ctrl+L = logs from all"
-	case TypePod:
-		ff.lines[1] = "1 = get logs   3 = describe     5 = scale   Ctrl+E = exec to all     Ctrl+K = follow logs from all"
-		ff.lines[2] = "2 = exec       4 = delete pod               Ctrl+L = logs from all"
-	case TypeContainer:
-		ff.lines[1] = "1 = get logs   Ctrl+E = exec to all     Ctrl+K = follow logs from all"
-		ff.lines[2] = "2 = exec       Ctrl+L = logs from all"
-	default:
-		ff.lines[1] = ""
-		ff.lines[2] = ""
-	}
+func (ff *FooterFrame) updateShortcutInfo(s tcell.Screen, help []string) {
+	lines := formatColumns(help, len(ff.lines)-1)
+	copy(ff.lines[1:], lines)
 	ff.update(s)
 }
 
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// guard decides which changes are allowed. In read-only mode mutating shortcuts and keys are disabled, changes in
+// protected contexts need extra confirmation.
+type guard struct {
+	readOnly bool
+	// protected contexts by name.
+	protected map[string]bool
+}
+
+// newGuard finds protected contexts of the group. Contexts without a setting in config.json are protected when
+// their name has a "prod" or "production" word.
+func newGuard(readOnly bool, group Group, config Config) guard {
+	protected := make(map[string]bool)
+	for _, nsGroup := range group.NsGroups {
+		context := nsGroup.Context
+		if settings, ok := config.Contexts[context]; ok && settings.Protected != nil {
+			protected[context] = *settings.Protected
+			continue
+		}
+		protected[context] = isProduction(context)
+	}
+	return guard{readOnly: readOnly, protected: protected}
+}
+
+func (g guard) isProtected(context string) bool {
+	return g.protected[context]
+}
+
+// protectedContexts returns sorted names of protected contexts.
+func (g guard) protectedContexts() []string {
+	contexts := make([]string, 0)
+	for context, protected := range g.protected {
+		if protected {
+			contexts = append(contexts, context)
+		}
+	}
+	sort.Strings(contexts)
+	return contexts
+}
+
+// banner returns header text which warns about read-only mode and protected contexts, it is empty when there is
+// nothing to warn about.
+func (g guard) banner() string {
+	values := make([]string, 0)
+	if g.readOnly {
+		values = append(values, "READ-ONLY")
+	}
+	if contexts := g.protectedContexts(); len(contexts) > 0 {
+		values = append(values, fmt.Sprintf("PROTECTED: %v", strings.Join(contexts, ", ")))
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return " " + strings.Join(values, " | ") + " "
+}
+
+// filter leaves out mutating shortcuts in read-only mode.
+func (g guard) filter(shortcuts []shortcut) []shortcut {
+	if !g.readOnly {
+		return shortcuts
+	}
+	result := make([]shortcut, 0)
+	for _, sc := range shortcuts {
+		if !sc.mutating {
+			result = append(result, sc)
+		}
+	}
+	return result
+}
+
+// isProduction reports whether the context name looks like production, it is used when the context has no
+// protected setting in config.json. Names are split into words on anything else than letters and digits, so that
+// e.g. "eu-prod-1" and "arn:aws:eks:eu-west-1:1234:cluster/production" match, but "nonprod" and "product-dev" don't.
+func isProduction(context string) bool {
+	words := strings.FieldsFunc(strings.ToLower(context), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if word == "prod" || word == "production" {
+			return true
+		}
+	}
+	return false
+}
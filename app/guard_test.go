@@ -0,0 +1,96 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewGuard(t *testing.T) {
+	protected, unprotected := true, false
+	group := Group{NsGroups: []NsGroup{
+		{Context: "eu-Production-1"}, {Context: "prod-legacy"}, {Context: "staging"}, {Context: "dev"}, {Context: "dev"},
+	}}
+	config := Config{Contexts: map[string]ContextConfig{
+		"prod-legacy": {Protected: &unprotected},
+		"staging":     {Protected: &protected},
+		"dev":         {},
+	}}
+	g := newGuard(false, group, config)
+	expected := []string{"eu-Production-1", "staging"}
+	if contexts := g.protectedContexts(); !reflect.DeepEqual(contexts, expected) {
+		t.Errorf("Invalid protected contexts. Want: %v, Got: %v", expected, contexts)
+	}
+	if banner := g.banner(); banner != " PROTECTED: eu-Production-1, staging " {
+		t.Errorf("Invalid banner: %q", banner)
+	}
+	if banner := newGuard(true, Group{NsGroups: []NsGroup{{Context: "dev"}}}, Config{}).banner(); banner != " READ-ONLY " {
+		t.Errorf("Invalid banner: %q", banner)
+	}
+}
+
+func TestIsProduction(t *testing.T) {
+	testTable := []struct {
+		context  string
+		expected bool
+	}{
+		{"prod", true},
+		{"eu-prod-1", true},
+		{"gke_payments_europe-west1_Production", true},
+		{"arn:aws:eks:eu-west-1:123456789012:cluster/prod", true},
+		{"admin@prod.example.com", true},
+		{"nonprod", false},
+		{"preprod", false},
+		{"product-dev", false},
+		{"prd", false},
+		{"live", false},
+		{"eu-prod2", false},
+	}
+	for _, tc := range testTable {
+		if result := isProduction(tc.context); result != tc.expected {
+			t.Errorf("Invalid result for %q. Want: %v, Got: %v", tc.context, tc.expected, result)
+		}
+	}
+}
+
+func TestReadOnlyShortcuts(t *testing.T) {
+	ns := &Namespace{name: "ns", context: "dev"}
+	pg := &PodGroup{name: "web", kind: KindDeployment, namespace: ns}
+	pod := &Pod{name: "web-1", podGroup: pg, containers: []Container{{name: "app"}}}
+	pod.containers[0].pod = pod
+
+	testTable := []struct {
+		item     Item
+		expected []string
+	}{
+		{pg, []string{"1 = describe", "Ctrl+L = logs from all", "Ctrl+K = follow logs from all", "l = view logs"}},
+		{pod, []string{"1 = get logs", "3 = describe", "Ctrl+L = logs from all", "Ctrl+K = follow logs from all", "l = view logs"}},
+		{&pod.containers[0], []string{"1 = get logs", "Ctrl+L = logs from all", "Ctrl+K = follow logs from all", "l = view logs"}},
+	}
+	g := guard{readOnly: true}
+	for _, tc := range testTable {
+		help := shortcutHelp(tc.item, g.filter(shortcuts(tc.item)), true)
+		if !reflect.DeepEqual(help, tc.expected) {
+			t.Errorf("Invalid help.\nWant: %q\nGot:  %q", tc.expected, help)
+		}
+	}
+}
+
+func TestReadOnlyActions(t *testing.T) {
+	actions, err := newActionSet(map[string][]Action{
+		"podGroup": {
+			{Key: "i", Label: "name", Template: "{{.PodGroup}}"},
+			{Key: "h", Label: "history", Template: "{{.Kubectl}} rollout history {{.Kind}}/{{.PodGroup}}", Mode: ActionModeRun},
+			{Key: "k", Label: "k9s", Template: "k9s --context {{.Context}}", Mode: ActionModeLaunch},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pg := &PodGroup{name: "web", kind: KindDeployment, namespace: &Namespace{name: "ns", context: "dev"}}
+
+	help := shortcutHelp(pg, guard{readOnly: true}.filter(actions.shortcuts(pg)), true)
+	expected := []string{"1 = describe", "i = name", "Ctrl+L = logs from all", "Ctrl+K = follow logs from all", "l = view logs"}
+	if !reflect.DeepEqual(help, expected) {
+		t.Errorf("Invalid help.\nWant: %q\nGot:  %q", expected, help)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/JLevconoks/k8ConsoleViewer/clipboard"
 	"github.com/JLevconoks/k8ConsoleViewer/terminal"
 	"github.com/gdamore/tcell"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -35,15 +37,30 @@ type Gui struct {
 	footerFrame *FooterFrame
 	popupFrame  *PopupFrame
 	statusBarCh chan string
+	// input is an active status bar input, e.g. search query, nil otherwise.
+	input       *inputLine
+	searchQuery string
+	logFrame    *LogFrame
+	execFrame   *ExecFrame
+	runFrame    *RunFrame
+	k8Client    K8Client
+	// launcher opens commands of Ctrl shortcuts in terminal windows.
+	launcher  terminal.Launcher
+	clipboard *clipboard.Clipboard
+	// actions are user defined shortcuts from config.json.
+	actions actionSet
+	guard   guard
+	banner  StringItem
 }
 
-func NewGui(s tcell.Screen, name string) Gui {
+func NewGui(s tcell.Screen, name string, k8Client K8Client, launcher terminal.Launcher, clip *clipboard.Clipboard, actions actionSet, guard guard) Gui {
 	sw, sh := s.Size()
 
 	currentTime := StringItem{0, 0, 30, time.Now().Format(time.RFC1123Z)}
 	execLabel := StringItem{currentTime.length + 3, 0, 17, "Time to execute: "}
 	execTime := StringItem{execLabel.x + execLabel.length, 0, 0, "0ms"}
 	groupName := StringItem{0, 1, 0, fmt.Sprintf("Group: %v", name)}
+	banner := StringItem{len(groupName.value) + 3, 1, 0, guard.banner()}
 
 	footerFrame := NewFooterFrame(s)
 
@@ -57,6 +74,12 @@ func NewGui(s tcell.Screen, name string) Gui {
 		footerFrame: footerFrame,
 		popupFrame:  NewPopupFrame(s, "", nil, nil),
 		statusBarCh: footerFrame.statusBarCh,
+		k8Client:    k8Client,
+		launcher:    launcher,
+		clipboard:   clip,
+		actions:     actions,
+		guard:       guard,
+		banner:      banner,
 	}
 }
 
@@ -65,6 +88,10 @@ func (gui *Gui) show(s tcell.Screen) {
 	gui.execLabel.Draw(s)
 	gui.execTime.Draw(s)
 	gui.groupName.Draw(s)
+	if gui.banner.value != "" {
+		drawRunes(s, gui.banner.value, gui.banner.x, gui.banner.y, gui.banner.Len(s)+gui.banner.x,
+			tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorRed).Bold(true))
+	}
 	gui.mainFrame.namespaceHeader.Draw(s)
 	gui.mainFrame.podHeader.Draw(s)
 	s.Show()
@@ -85,6 +112,10 @@ func (gui *Gui) updateNamespaces(s tcell.Screen, podListResults []PodListResult,
 }
 
 func (gui *Gui) redraw(s tcell.Screen) {
+	if gui.fullScreenFrameVisible() {
+		// Log, exec and run frames cover the whole screen and redraw themselves.
+		return
+	}
 	gui.mainFrame.refresh(s)
 	gui.updateStatusFrame()
 	if gui.popupFrame != nil && gui.popupFrame.visible {
@@ -94,24 +125,14 @@ func (gui *Gui) redraw(s tcell.Screen) {
 }
 
 func (gui *Gui) handleKeyDown() {
-	if gui.popupFrame.visible {
-		gui.popupFrame.moveCursorDown(gui.s)
-	} else {
-		gui.mainFrame.moveCursor(gui.s, 1)
-		gui.updateStatusFrame()
-	}
-
+	gui.mainFrame.moveCursor(gui.s, 1)
+	gui.updateStatusFrame()
 	gui.s.Show()
 }
 
 func (gui *Gui) handleKeyUp() {
-	if gui.popupFrame.visible {
-		gui.popupFrame.moveCursorUp(gui.s)
-	} else {
-		gui.mainFrame.moveCursor(gui.s, -1)
-		gui.updateStatusFrame()
-	}
-
+	gui.mainFrame.moveCursor(gui.s, -1)
+	gui.updateStatusFrame()
 	gui.s.Show()
 }
 
@@ -127,6 +148,18 @@ func (gui *Gui) handleKeyRight() {
 }
 
 func (gui *Gui) handleResize() {
+	if gui.logFrame != nil && gui.logFrame.visible {
+		gui.logFrame.resize(gui.s)
+		return
+	}
+	if gui.execFrame != nil && gui.execFrame.visible {
+		gui.execFrame.resize(gui.s)
+		return
+	}
+	if gui.runFrame != nil && gui.runFrame.visible {
+		gui.runFrame.resize(gui.s)
+		return
+	}
 	winWidth, winHeight := gui.s.Size()
 	gui.mainFrame.resize(gui.s, winWidth, winHeight)
 	gui.footerFrame.resize(gui.s, winWidth, winHeight)
@@ -167,33 +200,553 @@ func (gui *Gui) handleEndKey() {
 	gui.s.Show()
 }
 
+// showPopup replaces the current popup, while it is visible it receives all key events.
+func (gui *Gui) showPopup(popup *PopupFrame) {
+	gui.popupFrame = popup
+	gui.popupFrame.visible = true
+	gui.popupFrame.show(gui.s)
+	gui.s.Show()
+}
+
 func (gui *Gui) hidePopupFrame() {
 	gui.popupFrame.visible = false
+	gui.s.HideCursor()
 	gui.redraw(gui.s)
 }
 
-func (gui *Gui) handleEnterKey() {
-	if gui.popupFrame.visible {
-		selected := gui.popupFrame.items[gui.popupFrame.cursorYPos]
-		gui.popupFrame.callback(selected)
-		gui.popupFrame.visible = false
+func (gui *Gui) handlePopupKey(ev *tcell.EventKey) {
+	pf := gui.popupFrame
+	switch pf.handleKey(ev) {
+	case popupCancelled:
+		gui.hidePopupFrame()
+	case popupDone:
+		// Popup is hidden before the callback, which can show another popup.
+		pf.visible = false
+		gui.s.HideCursor()
+		pf.done()
 		gui.redraw(gui.s)
+	default:
+		pf.show(gui.s)
+		gui.s.Show()
+	}
+}
+
+func (gui *Gui) startInput(input *inputLine) {
+	gui.input = input
+	gui.statusBarCh <- input.String()
+}
+
+func (gui *Gui) handleInputKey(ev *tcell.EventKey) {
+	input := gui.input
+	if input.handleKey(ev) {
+		if gui.input == input {
+			gui.input = nil
+		}
+	} else {
+		gui.statusBarCh <- input.String()
+	}
+	gui.s.Show()
+}
+
+// startSearch moves cursor to matching items while query is typed, Enter keeps the query for searchNext.
+func (gui *Gui) startSearch() {
+	gui.startInput(&inputLine{
+		prompt: "/",
+		onChange: func(value string) {
+			gui.mainFrame.search(gui.s, value, true, true)
+			gui.updateStatusFrame()
+		},
+		onDone: func(value string, confirmed bool) {
+			if confirmed && value != "" {
+				gui.searchQuery = value
+			}
+			gui.statusBarCh <- ""
+		},
+	})
+}
+
+func (gui *Gui) searchNext(forward bool) {
+	if gui.searchQuery == "" {
+		return
+	}
+	if !gui.mainFrame.search(gui.s, gui.searchQuery, forward, false) {
+		gui.statusBarCh <- "Pattern not found: " + gui.searchQuery
+	}
+	gui.updateStatusFrame()
+	gui.s.Show()
+}
+
+// startFilter narrows main frame down while filter is typed, Esc restores the previous filter.
+func (gui *Gui) startFilter() {
+	previous := gui.mainFrame.filter
+	gui.startInput(&inputLine{
+		prompt: "Filter: ",
+		value:  previous,
+		onChange: func(value string) {
+			gui.mainFrame.setFilter(gui.s, value)
+			gui.updateStatusFrame()
+		},
+		onDone: func(value string, confirmed bool) {
+			if !confirmed {
+				gui.mainFrame.setFilter(gui.s, previous)
+				gui.updateStatusFrame()
+			}
+			gui.statusBarCh <- ""
+		},
+	})
+}
+
+func (gui *Gui) clearFilter() {
+	gui.mainFrame.setFilter(gui.s, "")
+	gui.updateStatusFrame()
+	gui.s.Show()
+}
+
+func (gui *Gui) handleProblemsOnly() {
+	gui.mainFrame.toggleProblemsOnly(gui.s)
+	gui.updateStatusFrame()
+	gui.s.Show()
+}
+
+// handleSortColumn sorts pods by the next column, handleSortOrder reverses the order.
+func (gui *Gui) handleSortColumn() {
+	gui.mainFrame.setPodSort(gui.s, gui.mainFrame.podSort.nextColumn())
+	gui.updateStatusFrame()
+	gui.s.Show()
+}
+
+func (gui *Gui) handleSortOrder() {
+	gui.mainFrame.setPodSort(gui.s, gui.mainFrame.podSort.reversed())
+	gui.updateStatusFrame()
+	gui.s.Show()
+}
+
+// showLogs opens log frame for the selected container, pod or all pods of a pod group. Container is chosen in a popup
+// when there are several.
+func (gui *Gui) showLogs() {
+	if len(gui.mainFrame.positions) == 0 {
+		return
+	}
+	item := gui.mainFrame.positions[gui.mainFrame.cursorFullPosition()]
+	ns, podNames, contNames := gatherContainerInfos(item)
+	switch item.Type() {
+	case TypePod:
+		podNames = []string{item.(*Pod).name}
+	case TypeContainer:
+		c := item.(*Container)
+		podNames, contNames = []string{c.pod.name}, []string{c.name}
+	}
+	if ns == nil || len(contNames) == 0 {
+		return
+	}
+
+	gui.selectContainer(contNames, func(container string) {
+		gui.logFrame = NewLogFrame(gui.s, gui.k8Client, ns, podNames, container)
+		gui.logFrame.open(gui.s)
+	})
+}
+
+// selectContainer calls callback straight away when there is a single container, otherwise it shows a popup.
+func (gui *Gui) selectContainer(contNames []string, callback func(string)) {
+	if len(contNames) == 1 {
+		callback(contNames[0])
+		return
+	}
+	gui.showPopup(NewPopupFrame(gui.s, "Container", contNames, callback))
+}
+
+func (gui *Gui) closeLogs() {
+	gui.logFrame.close()
+	gui.logFrame = nil
+	gui.restoreMainFrame()
+}
+
+func (gui *Gui) fullScreenFrameVisible() bool {
+	return (gui.logFrame != nil && gui.logFrame.visible) || (gui.execFrame != nil && gui.execFrame.visible) ||
+		(gui.runFrame != nil && gui.runFrame.visible)
+}
+
+// restoreMainFrame repaints the whole screen after a full screen frame was closed.
+func (gui *Gui) restoreMainFrame() {
+	gui.s.HideCursor()
+	gui.s.Clear()
+	gui.show(gui.s)
+	gui.handleResize()
+	gui.updateStatusFrame()
+	gui.s.Show()
+}
+
+func (gui *Gui) handleLogKey(ev *tcell.EventKey) {
+	lf := gui.logFrame
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		gui.closeLogs()
+		return
+	case tcell.KeyUp:
+		lf.scroll(-1)
+	case tcell.KeyDown:
+		lf.scroll(1)
+	case tcell.KeyPgUp:
+		lf.scroll(-lf.linesHeight())
+	case tcell.KeyPgDn:
+		lf.scroll(lf.linesHeight())
+	case tcell.KeyHome:
+		lf.scroll(-len(lf.lines))
+	case tcell.KeyEnd:
+		lf.scroll(len(lf.lines))
+	}
+
+	switch ev.Rune() {
+	case 'q':
+		gui.closeLogs()
+	case 'p':
+		lf.toggleFollow()
+	case 'P':
+		options := lf.currentOptions()
+		options.previous = !options.previous
+		lf.setOptions(options)
+	case 't':
+		gui.startInput(&inputLine{
+			prompt: "Tail lines (empty for all): ",
+			value:  strconv.FormatInt(lf.currentOptions().tailLines, 10),
+			onDone: func(value string, confirmed bool) {
+				tail, err := parseTail(value)
+				gui.applyLogOption(confirmed, err, func(options *logOptions) { options.tailLines = tail })
+			},
+		})
+	case 's':
+		gui.startInput(&inputLine{
+			prompt: "Since, e.g. 10m or 1h (empty for all): ",
+			onDone: func(value string, confirmed bool) {
+				since, err := parseSince(value)
+				gui.applyLogOption(confirmed, err, func(options *logOptions) { options.sinceSeconds = since })
+			},
+		})
+	case '/':
+		previous := lf.query
+		gui.startInput(&inputLine{
+			prompt:   "/",
+			onChange: lf.setQuery,
+			onDone: func(value string, confirmed bool) {
+				gui.statusBarCh <- ""
+				if !confirmed {
+					lf.setQuery(previous)
+				} else if value != "" && !lf.searchNext(true) {
+					gui.statusBarCh <- "Pattern not found: " + value
+				}
+			},
+		})
+	case 'n', 'N':
+		if lf.query != "" && !lf.searchNext(ev.Rune() == 'n') {
+			gui.statusBarCh <- "Pattern not found: " + lf.query
+		}
+	case 'w':
+		gui.startInput(&inputLine{
+			prompt: "Save to: ",
+			value:  lf.defaultFileName(),
+			onDone: func(value string, confirmed bool) {
+				if !confirmed || value == "" {
+					gui.statusBarCh <- ""
+					return
+				}
+				if err := lf.save(value); err != nil {
+					gui.statusBarCh <- "Error: " + err.Error()
+					return
+				}
+				gui.statusBarCh <- "Logs saved to " + value
+			},
+		})
+	}
+}
+
+func (gui *Gui) applyLogOption(confirmed bool, err error, apply func(options *logOptions)) {
+	switch {
+	case !confirmed:
+		gui.statusBarCh <- ""
+	case err != nil:
+		gui.statusBarCh <- "Error: " + err.Error()
+	default:
+		gui.statusBarCh <- ""
+		options := gui.logFrame.currentOptions()
+		apply(&options)
+		gui.logFrame.setOptions(options)
+	}
+}
+
+// execInApp opens a shell of the selected pod or container in exec frame, container is chosen in a popup when there
+// are several. Shell is probed first when it is not known for the container image.
+func (gui *Gui) execInApp() {
+	if len(gui.mainFrame.positions) == 0 || gui.execDisabled() {
+		return
+	}
+	var containers []*Container
+	switch item := gui.mainFrame.positions[gui.mainFrame.cursorFullPosition()].(type) {
+	case *Pod:
+		for index := range item.containers {
+			containers = append(containers, &item.containers[index])
+		}
+	case *Container:
+		containers = []*Container{item}
+	}
+	if len(containers) == 0 {
+		return
+	}
+
+	contNames := make([]string, len(containers))
+	for index, c := range containers {
+		contNames[index] = c.name
+	}
+	gui.selectContainer(contNames, func(selected string) {
+		for _, c := range containers {
+			if c.name != selected {
+				continue
+			}
+			c := c
+			ns := c.pod.podGroup.namespace
+			gui.confirmProtected(ns.context, fmt.Sprintf("Exec in %v", c.pod.name), func() {
+				gui.withShell(c, func(shell []string) {
+					gui.execFrame = NewExecFrame(gui.s, gui.k8Client, ns, c.pod.name, c.name, shell)
+					gui.s.Clear()
+					gui.execFrame.open(gui.s)
+				})
+			})
+		}
+	})
+}
+
+// withShell calls callback with the shell of the container, probing it in the background when it is not known yet.
+// A debug container is offered instead when the container has no shell.
+func (gui *Gui) withShell(c *Container, callback func(shell []string)) {
+	if shell, probed := gui.mainFrame.containerShell(c); probed {
+		if len(shell) == 0 {
+			gui.offerDebugContainer(c)
+			return
+		}
+		callback(shell)
+		return
+	}
+
+	gui.statusBarCh <- fmt.Sprintf("Detecting shell in %v/%v...", c.pod.name, c.name)
+	go func() {
+		shell, err := probeShell(gui.k8Client, c.pod.podGroup.namespace, c.pod.name, c.name)
+		// Result is handled in the event loop, like key events.
+		_ = gui.s.PostEvent(tcell.NewEventInterrupt(func() {
+			if err != nil {
+				gui.statusBarCh <- "Error: " + err.Error()
+				return
+			}
+			gui.statusBarCh <- ""
+			gui.mainFrame.setShell(c, shell)
+			gui.withShell(c, callback)
+		}))
+	}()
+}
+
+// offerDebugContainer shows a popup which copies a command starting a debug container next to the container.
+func (gui *Gui) offerDebugContainer(c *Container) {
+	command := c.debugCommand()
+	title := fmt.Sprintf("No shell in %v", c.name)
+	gui.showPopup(NewPopupFrame(gui.s, title, []string{"Copy debug container command"}, func(string) {
+		gui.copyToClipboard(command)
+	}))
+}
+
+func (gui *Gui) handleExecKey(ev *tcell.EventKey) {
+	if ev.Key() == tcell.KeyCtrlRightSq {
+		gui.closeExec()
+		return
+	}
+	gui.execFrame.handleKey(ev)
+}
+
+func (gui *Gui) closeExec() {
+	gui.execFrame.close()
+	gui.execFrame = nil
+	gui.restoreMainFrame()
+}
+
+// handleExecEnded closes exec frame when its session ends, events of frames which were closed already are ignored.
+func (gui *Gui) handleExecEnded(ended execEnded) {
+	if gui.execFrame != ended.frame {
+		return
+	}
+	gui.closeExec()
+	if ended.err != nil {
+		gui.statusBarCh <- fmt.Sprintf("Exec %v: %v", ended.frame.pod, ended.err)
+		return
+	}
+	gui.statusBarCh <- fmt.Sprintf("Exec %v: session ended", ended.frame.pod)
+}
+
+// runInAll asks for a command and runs it in the chosen container of all pods of the selected pod group, revision
+// or pod's group. Command runs in the shell which was probed in the first pod.
+func (gui *Gui) runInAll() {
+	if len(gui.mainFrame.positions) == 0 || gui.execDisabled() {
+		return
+	}
+	item := gui.mainFrame.positions[gui.mainFrame.cursorFullPosition()]
+	ns, podNames, contNames := gatherContainerInfos(item)
+	if ns == nil || len(contNames) == 0 {
+		return
+	}
+	var firstPod *Pod
+	switch item := item.(type) {
+	case *PodGroup:
+		firstPod = &item.pods[0]
+	case *Revision:
+		firstPod = item.pods[0]
+	case *Pod:
+		firstPod = &item.podGroup.pods[0]
+	case *Container:
+		firstPod = &item.pod.podGroup.pods[0]
+	}
+
+	gui.selectContainer(contNames, func(container string) {
+		var c *Container
+		for index := range firstPod.containers {
+			if firstPod.containers[index].name == container {
+				c = &firstPod.containers[index]
+			}
+		}
+		if c == nil {
+			gui.statusBarCh <- fmt.Sprintf("Error: container %v is not running in %v", container, firstPod.name)
+			return
+		}
+		gui.selectPods(podNames, func(podNames []string) {
+			gui.startInput(&inputLine{
+				prompt: fmt.Sprintf("Run in %d pods -c %v: ", len(podNames), container),
+				onDone: func(value string, confirmed bool) {
+					gui.statusBarCh <- ""
+					if !confirmed || strings.TrimSpace(value) == "" {
+						return
+					}
+					gui.confirmProtected(ns.context, fmt.Sprintf("Run in %d pods", len(podNames)), func() {
+						gui.withShell(c, func(shell []string) {
+							gui.runFrame = NewRunFrame(gui.s, gui.k8Client, ns, podNames, container, shell, value)
+							gui.s.Clear()
+							gui.runFrame.open(gui.s)
+						})
+					})
+				},
+			})
+		})
+	})
+}
+
+// selectPods shows a multi select popup with all pods checked, unless there is a single pod.
+func (gui *Gui) selectPods(podNames []string, callback func([]string)) {
+	if len(podNames) == 1 {
+		callback(podNames)
+		return
+	}
+	gui.showPopup(NewMultiSelectPopup(gui.s, "Pods (Space = toggle, Ctrl+A = all)", podNames, true, func(selected []string) {
+		if len(selected) == 0 {
+			gui.statusBarCh <- "No pods selected"
+			return
+		}
+		callback(selected)
+	}))
+}
+
+func (gui *Gui) closeRun() {
+	gui.runFrame.close()
+	gui.runFrame = nil
+	gui.restoreMainFrame()
+}
+
+func (gui *Gui) handleRunKey(ev *tcell.EventKey) {
+	rf := gui.runFrame
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		gui.closeRun()
+		return
+	case tcell.KeyUp:
+		rf.moveCursor(-1)
+	case tcell.KeyDown:
+		rf.moveCursor(1)
+	case tcell.KeyPgUp:
+		rf.moveCursor(-rf.rowsHeight())
+	case tcell.KeyPgDn:
+		rf.moveCursor(rf.rowsHeight())
+	case tcell.KeyHome:
+		rf.moveCursor(-len(rf.results))
+	case tcell.KeyEnd:
+		rf.moveCursor(len(rf.results))
+	case tcell.KeyEnter, tcell.KeyLeft, tcell.KeyRight:
+		rf.toggleExpanded()
+	}
+
+	switch ev.Rune() {
+	case 'q':
+		gui.closeRun()
+	case 'y':
+		gui.copyToClipboard(rf.selectedOutput())
+	case 'Y':
+		gui.copyToClipboard(rf.content())
 	}
 }
 
 func (gui *Gui) execToPods() {
-	cmdTemplate := "kubectl --context %v -n %v exec -it %v -c %v -- /bin/bash"
-	gui.handleCommandExec(cmdTemplate)
+	if gui.execDisabled() {
+		return
+	}
+	gui.handleCommandExec(true, func(pod, container string) []string {
+		return []string{"exec", "-it", pod, "-c", container, "--", defaultExecShell}
+	})
 }
 
 func (gui *Gui) getLogsFromPods() {
-	cmdTemplate := "kubectl --context %v -n %v logs %v -c %v"
-	gui.handleCommandExec(cmdTemplate)
+	gui.handleCommandExec(false, func(pod, container string) []string {
+		return []string{"logs", pod, "-c", container}
+	})
 }
 
 func (gui *Gui) getLogsAndFollowFromPods() {
-	cmdTemplate := "kubectl --context %v -n %v logs %v -c %v -f"
-	gui.handleCommandExec(cmdTemplate)
+	gui.handleCommandExec(false, func(pod, container string) []string {
+		return []string{"logs", pod, "-c", container, "-f"}
+	})
+}
+
+// mainKey is a key of the main frame handled by the app before shortcuts and actions.
+type mainKey struct {
+	key    rune
+	handle func(gui *Gui)
+}
+
+// mainKeys are handled in this order, actions can't use their keys.
+var mainKeys = []mainKey{
+	{'c', (*Gui).handleCollapseAll},
+	{'e', (*Gui).handleExpandAll},
+	{'f', (*Gui).startFilter},
+	{'n', func(gui *Gui) { gui.searchNext(true) }},
+	{'N', func(gui *Gui) { gui.searchNext(false) }},
+	{'u', (*Gui).handleProblemsOnly},
+	{'s', (*Gui).handleSortColumn},
+	{'S', (*Gui).handleSortOrder},
+	{'l', (*Gui).showLogs},
+	{'x', (*Gui).execInApp},
+	{'r', (*Gui).runInAll},
+	{'/', (*Gui).startSearch},
+}
+
+// reservedKeys returns keys of mainKeys as a string.
+func reservedKeys() string {
+	var keys strings.Builder
+	for _, mk := range mainKeys {
+		keys.WriteRune(mk.key)
+	}
+	return keys.String()
+}
+
+// handleMainRune handles a key of the main frame, keys which are not in mainKeys are shortcuts and actions.
+func (gui *Gui) handleMainRune(r rune) {
+	for _, mk := range mainKeys {
+		if mk.key == r {
+			mk.handle(gui)
+			return
+		}
+	}
+	gui.handleRune(r)
 }
 
 func (gui *Gui) handleRune(r rune) {
@@ -202,74 +755,151 @@ func (gui *Gui) handleRune(r rune) {
 	}
 	position := gui.mainFrame.cursorFullPosition()
 	item := gui.mainFrame.positions[position]
-	var value string
-	switch item.Type() {
-	case TypeNamespace:
-		ns := item.(*Namespace)
-		switch r {
-		case '1':
-			value = fmt.Sprintf("kubectl --context %v -n %v get all", ns.context, ns.name)
-		case '2':
-			value = fmt.Sprintf("kubectl --context %v -n %v get ingress", ns.context, ns.name)
-		case '3':
-			value = fmt.Sprintf("kubectl --context %v -n %v get ev --sort-by=.lastTimestamp", ns.context, ns.name)
-		case '4':
-			value = fmt.Sprintf("kubectl --context %v describe ns %v", ns.context, ns.name)
-		case '5':
-			value = fmt.Sprintf("kubectl --context %v -n %v get secrets", ns.context, ns.name)
-		case '6':
-			value = fmt.Sprintf("kubectl --context %v -n %v get cm", ns.context, ns.name)
+	for _, sc := range gui.shortcuts(item) {
+		if sc.key != r {
+			continue
 		}
-	case TypePodGroup:
-		pg := item.(*PodGroup)
-		context := pg.namespace.context
-		nsName := pg.namespace.name
-		switch r {
-		case '1':
-			value = fmt.Sprintf("kubectl --context %v -n %v describe deployment %v", context, nsName, pg.name)
-		case '2':
-			value = fmt.Sprintf("kubectl --context %v -n %v delete deployment %v", context, nsName, pg.name)
-		case '3':
-			value = fmt.Sprintf("kubectl --context %v -n %v scale deployment %v --replicas=", context, nsName, pg.name)
+		if sc.mode != "" {
+			gui.runAction(sc)
+			return
 		}
-	case TypePod:
-		pod := item.(*Pod)
-		context := pod.podGroup.namespace.context
-		nsName := pod.podGroup.namespace.name
-		switch r {
-		case '1':
-			value = fmt.Sprintf("kubectl --context %v -n %v logs %v", context, nsName, pod.name)
-		case '2':
-			value = fmt.Sprintf("kubectl --context %v -n %v exec -it %v -- /bin/bash", context, nsName, pod.name)
-		case '3':
-			value = fmt.Sprintf("kubectl --context %v -n %v describe pod %v", context, nsName, pod.name)
-		case '4':
-			value = fmt.Sprintf("kubectl --context %v -n %v delete pod %v", context, nsName, pod.name)
-		case '5':
-			value = fmt.Sprintf("kubectl --context %v -n %v scale deployment %v --replicas=", context, nsName, pod.podGroup.name)
+		if sc.mutation != nil {
+			gui.confirmMutation(*sc.mutation)
+			return
 		}
-	case TypeContainer:
-		cont := item.(*Container)
-		context := cont.pod.podGroup.namespace.context
-		nsName := cont.pod.podGroup.namespace.name
-		switch r {
-		case '1':
-			value = fmt.Sprintf("kubectl --context %v -n %v logs %v -c %v", context, nsName, cont.pod.name, cont.name)
-		case '2':
-			value = fmt.Sprintf("kubectl --context %v -n %v exec -it %v -c %v -- /bin/bash", context, nsName, cont.pod.name, cont.name)
+		// Exec commands are copied with the shell found by in-app exec, copying doesn't probe it, as that runs commands
+		// in the container. Containers known to have no shell get a debug container instead.
+		if sc.container != nil {
+			if shell, probed := gui.mainFrame.containerShell(sc.container); probed && len(shell) == 0 {
+				gui.offerDebugContainer(sc.container)
+				return
+			}
 		}
+		gui.copyToClipboard(sc.command)
+		return
 	}
+}
+
+// shortcuts returns shortcuts and actions of the item, mutating ones are left out in read-only mode.
+func (gui *Gui) shortcuts(item Item) []shortcut {
+	return gui.guard.filter(gui.actions.shortcuts(item))
+}
 
-	if value == "" {
+// execDisabled reports that exec is not allowed in read-only mode.
+func (gui *Gui) execDisabled() bool {
+	if gui.guard.readOnly {
+		gui.statusBarCh <- "Exec is disabled in read-only mode"
+	}
+	return gui.guard.readOnly
+}
+
+// confirmProtected calls callback right away, or after a confirmation when the context is protected.
+func (gui *Gui) confirmProtected(context, title string, callback func()) {
+	if !gui.guard.isProtected(context) {
+		callback()
 		return
 	}
-	gui.statusBarCh <- "Clipboard: " + value
-	err := clipboard.ToClipboard(value)
+	gui.showPopup(NewConfirmPopup(gui.s, fmt.Sprintf("%v in protected context %v?", title, context), func(confirmed bool) {
+		if confirmed {
+			callback()
+		}
+	}))
+}
+
+// confirmMutation asks for replica count when scaling and confirms the change in a popup, production contexts also
+// require typing the resource name. The change is made in the background and its result is shown as a toast.
+func (gui *Gui) confirmMutation(m mutation) {
+	if m.verb != mutationScale {
+		gui.confirm(fmt.Sprintf("Delete %v?", m.target()), m, func() { gui.applyMutation(m) })
+		return
+	}
+	validate := func(value string) error {
+		_, err := parseReplicas(value)
+		return err
+	}
+	title := fmt.Sprintf("Scale %v to replicas", m.target())
+	gui.showPopup(NewInputPopup(gui.s, title, strconv.Itoa(int(m.replicas)), validate, func(value string) {
+		m.replicas, _ = parseReplicas(value)
+		gui.confirm(fmt.Sprintf("Scale %v to %d?", m.target(), m.replicas), m, func() { gui.applyMutation(m) })
+	}))
+}
 
+// confirm shows a yes/no popup, which defaults to no, and calls onConfirmed when yes is selected.
+func (gui *Gui) confirm(title string, m mutation, onConfirmed func()) {
+	gui.showPopup(NewConfirmPopup(gui.s, title, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if !gui.guard.isProtected(m.namespace.context) {
+			onConfirmed()
+			return
+		}
+		validate := func(value string) error {
+			if value != m.name {
+				return fmt.Errorf("type %v to confirm", m.name)
+			}
+			return nil
+		}
+		title := fmt.Sprintf("%v is a protected context, type %v", m.namespace.context, m.name)
+		gui.showPopup(NewInputPopup(gui.s, title, "", validate, func(string) { onConfirmed() }))
+	}))
+}
+
+func (gui *Gui) applyMutation(m mutation) {
+	gui.statusBarCh <- fmt.Sprintf("%v %v...", strings.Title(m.verb), m.target())
+	go func() {
+		var err error
+		result := ""
+		ns := m.namespace
+		switch m.verb {
+		case mutationDelete:
+			err = gui.k8Client.deleteResource(ns.cluster(), ns.name, m.kind, m.name)
+			result = fmt.Sprintf("Deleted %v", m.target())
+		case mutationScale:
+			err = gui.k8Client.scale(ns.cluster(), ns.name, m.kind, m.name, m.replicas)
+			result = fmt.Sprintf("Scaled %v to %d", m.target(), m.replicas)
+		}
+		if err != nil {
+			gui.showToast(fmt.Sprintf("Error: %v %v: %v", m.verb, m.target(), err), true)
+			return
+		}
+		gui.showToast(result, false)
+	}()
+}
+
+func (gui *Gui) showToast(value string, isError bool) {
+	gui.footerFrame.toastCh <- toast{value: value, isError: isError}
+}
+
+// runAction copies, launches or runs command of an action from config.json.
+func (gui *Gui) runAction(sc shortcut) {
+	if sc.err != nil {
+		gui.statusBarCh <- fmt.Sprintf("Error (%v): %v", sc.label, sc.err)
+		return
+	}
+	switch sc.mode {
+	case ActionModeCopy:
+		gui.copyToClipboard(sc.command)
+	case ActionModeLaunch:
+		if err := gui.launcher.Launch([][]string{{"sh", "-c", sc.command}}); err != nil {
+			gui.statusBarCh <- fmt.Sprintf("Error (%v): %v", gui.launcher.Name(), err)
+			return
+		}
+		gui.statusBarCh <- fmt.Sprintf("Opened %v in %v", sc.label, gui.launcher.Name())
+	case ActionModeRun:
+		gui.runFrame = NewLocalRunFrame(gui.s, sc.label, sc.command)
+		gui.s.Clear()
+		gui.runFrame.open(gui.s)
+	}
+}
+
+func (gui *Gui) copyToClipboard(value string) {
+	backend, err := gui.clipboard.Copy(value)
 	if err != nil {
 		gui.statusBarCh <- "Error: " + err.Error()
 		return
 	}
+	gui.statusBarCh <- fmt.Sprintf("Clipboard (%v): %v", backend, value)
 }
 
 func (gui *Gui) updateStatusFrame() {
@@ -278,10 +908,12 @@ func (gui *Gui) updateStatusFrame() {
 		return
 	}
 	item := gui.mainFrame.positions[gui.mainFrame.cursorFullPosition()]
-	gui.footerFrame.updateShortcutInfo(gui.s, item)
+	gui.footerFrame.updateShortcutInfo(gui.s, shortcutHelp(item, gui.shortcuts(item), gui.guard.readOnly))
 }
 
-func (gui *Gui) handleCommandExec(tmpl string) {
+// handleCommandExec opens kubectl command returned by args for every pod in the launcher, after a container is selected.
+// Exec commands are confirmed in protected contexts.
+func (gui *Gui) handleCommandExec(exec bool, args func(pod, container string) []string) {
 	// TODO need to do something better regarding this check.
 	if len(gui.mainFrame.positions) == 0 {
 		return
@@ -290,55 +922,70 @@ func (gui *Gui) handleCommandExec(tmpl string) {
 	position := gui.mainFrame.cursorFullPosition()
 	item := gui.mainFrame.positions[position]
 
-	context, nsName, podNames, contNames := gatherContainerInfos(item)
+	ns, podNames, contNames := gatherContainerInfos(item)
+	if ns == nil {
+		return
+	}
 
 	popupCallback := func(selected string) {
-		commands := assembleCommands(tmpl, context, nsName, selected, podNames)
-		if len(commands) > 0 {
-			err := terminal.OpenAndExecute(commands)
+		commands := assembleCommands(ns.kubectlArgs(), ns.name, selected, podNames, args)
+		if len(commands) == 0 {
+			return
+		}
+		launch := func() {
+			err := gui.launcher.Launch(commands)
 			if err != nil {
-				gui.statusBarCh <- err.Error()
+				gui.statusBarCh <- fmt.Sprintf("Error (%v): %v", gui.launcher.Name(), err)
+				return
 			}
+			gui.statusBarCh <- fmt.Sprintf("Opened %d commands in %v", len(commands), gui.launcher.Name())
+		}
+		if !exec {
+			launch()
+			return
 		}
+		gui.confirmProtected(ns.context, fmt.Sprintf("Exec in %d pods", len(commands)), launch)
 	}
-	gui.popupFrame = NewPopupFrame(gui.s, "Container", contNames, popupCallback)
-	gui.popupFrame.visible = true
-	gui.popupFrame.show(gui.s)
-	gui.s.Show()
+	gui.showPopup(NewPopupFrame(gui.s, "Container", contNames, popupCallback))
 }
 
-func gatherContainerInfos(item Item) (context, nsName string, podNames, contNames []string) {
+func gatherContainerInfos(item Item) (ns *Namespace, podNames, contNames []string) {
 	switch item.Type() {
 	case TypePodGroup:
 		pg := item.(*PodGroup)
-		context = pg.namespace.context
-		nsName = pg.namespace.name
+		ns = pg.namespace
+		// Controllers without pods have no containers to pick from.
+		if len(pg.pods) == 0 {
+			return nil, nil, nil
+		}
 		podNames = pg.podNames()
-		// TODO check for empty slice
 		contNames = pg.pods[0].containerNames()
+	case TypeRevision:
+		r := item.(*Revision)
+		ns = r.podGroup.namespace
+		podNames = r.podNames()
+		contNames = r.pods[0].containerNames()
 	case TypePod:
 		p := item.(*Pod)
-		context = p.podGroup.namespace.context
-		nsName = p.podGroup.namespace.name
+		ns = p.podGroup.namespace
 		podNames = p.podGroup.podNames()
 		contNames = p.containerNames()
 	case TypeContainer:
 		c := item.(*Container)
-		context = c.pod.podGroup.namespace.context
-		nsName = c.pod.podGroup.namespace.name
+		ns = c.pod.podGroup.namespace
 		podNames = c.pod.podGroup.podNames()
 		contNames = c.pod.containerNames()
 	}
 
-	return context, nsName, podNames, contNames
+	return ns, podNames, contNames
 }
 
-func assembleCommands(tmpl, context, nsName, contName string, pods []string) []string {
-	commands := make([]string, 0)
+func assembleCommands(kubectl []string, nsName, contName string, pods []string, args func(pod, container string) []string) [][]string {
+	commands := make([][]string, 0)
 
 	for _, podName := range pods {
-		cmdString := fmt.Sprintf(tmpl, context, nsName, podName, contName)
-		commands = append(commands, cmdString)
+		command := append(append([]string{}, kubectl...), "-n", nsName)
+		commands = append(commands, append(command, args(podName, contName)...))
 	}
 
 	return commands
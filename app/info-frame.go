@@ -18,6 +18,16 @@ type InfoFrame struct {
 	scrollYOffset    int
 	namespaceHeader  StringItem
 	podHeader        StringItem
+	viewInfo         StringItem
+	// filter narrows shown items to the ones with matching names and their ancestors.
+	filter string
+	// problemsOnly hides healthy items, hiddenCount is the number of items hidden by it.
+	problemsOnly bool
+	hiddenCount  int
+	// podSort is the order of pods, it is kept across refreshes.
+	podSort podSort
+	// shells are probed shells by image, they are applied to containers after every refresh.
+	shells           shellCache
 	positions        []Item
 	nsItems          []Namespace
 	nameColWidth     int
@@ -42,6 +52,7 @@ func NewInfoFrame(winWidth, winHeight int) *InfoFrame {
 		scrollYOffset:    0,
 		namespaceHeader:  nsHeader,
 		podHeader:        podHeader,
+		viewInfo:         StringItem{0, 2, 0, ""},
 		positions:        []Item{},
 		nsItems:          []Namespace{},
 		nameColWidth:     NameColumnDefaultWidth,
@@ -55,6 +66,7 @@ func NewInfoFrame(winWidth, winHeight int) *InfoFrame {
 func (f *InfoFrame) refresh(s tcell.Screen) {
 	f.clear(s)
 	f.updatePositions()
+	f.updateViewInfo(s)
 	f.updatePodHeader(s)
 	f.updateFrameInfo(s)
 	f.updateCursor(s)
@@ -70,33 +82,147 @@ func (f *InfoFrame) clear(s tcell.Screen) {
 
 func (f *InfoFrame) updatePositions() {
 	positions := make([]Item, 0)
+	f.hiddenCount = 0
 	for nsIndex := range f.nsItems {
-		positions = append(positions, &f.nsItems[nsIndex])
-		if f.nsItems[nsIndex].isExpanded {
-			if f.nsItems[nsIndex].nsMessage.message != "" {
-				positions = append(positions, &(f.nsItems[nsIndex].nsMessage))
-			}
-			if f.nsItems[nsIndex].nsError.error != nil {
-				positions = append(positions, &(f.nsItems[nsIndex].nsError))
-			}
+		positions = f.appendVisible(positions, &f.nsItems[nsIndex], false, false)
+	}
+	f.positions = positions
+}
 
-			for dIndex := range f.nsItems[nsIndex].deployments {
-				positions = append(positions, f.nsItems[nsIndex].deployments[dIndex])
-				if f.nsItems[nsIndex].deployments[dIndex].isExpanded {
-					for pIndex := range f.nsItems[nsIndex].deployments[dIndex].pods {
-						positions = append(positions, &(f.nsItems[nsIndex].deployments[dIndex].pods[pIndex]))
+// appendVisible adds item and its expanded children to positions, or all of its children when all is set.
+// With an active filter only matching items, their ancestors and descendants are added, ancestors of matching items
+// are shown expanded. In problems only view healthy items are left out and unhealthy ones are shown expanded.
+func (f *InfoFrame) appendVisible(positions []Item, item Item, ancestorMatches, all bool) []Item {
+	if f.problemsOnly && isItemHealthy(item) {
+		if !all {
+			f.hiddenCount += countItems(item)
+		}
+		return positions
+	}
+	matches := ancestorMatches || nameMatches(item, f.filter)
+	descendantMatches := f.filter != "" && f.descendantMatches(item)
+	if !matches && !descendantMatches {
+		return positions
+	}
+	positions = append(positions, item)
+	if all || item.IsExpanded() || descendantMatches || f.problemsOnly {
+		items := children(item)
+		f.podSort.sortPods(items)
+		for _, child := range items {
+			positions = f.appendVisible(positions, child, matches, all)
+		}
+	}
+	return positions
+}
 
-						if f.nsItems[nsIndex].deployments[dIndex].pods[pIndex].isExpanded {
-							for pContainer := range f.nsItems[nsIndex].deployments[dIndex].pods[pIndex].containers {
-								positions = append(positions, &(f.nsItems[nsIndex].deployments[dIndex].pods[pIndex].containers[pContainer]))
-							}
-						}
-					}
-				}
+func (f *InfoFrame) descendantMatches(item Item) bool {
+	for _, child := range children(item) {
+		if f.problemsOnly && isItemHealthy(child) {
+			continue
+		}
+		if nameMatches(child, f.filter) || f.descendantMatches(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchableItems returns all items passing the filter in display order, including children of collapsed items.
+func (f *InfoFrame) searchableItems() []Item {
+	items := make([]Item, 0)
+	for nsIndex := range f.nsItems {
+		items = f.appendVisible(items, &f.nsItems[nsIndex], false, true)
+	}
+	return items
+}
+
+// search moves cursor to the next item matching query, starting from the current item when includeCurrent is set.
+// Collapsed ancestors of the found item are expanded. It returns false when nothing matches.
+func (f *InfoFrame) search(s tcell.Screen, query string, forward, includeCurrent bool) bool {
+	items := f.searchableItems()
+	if query == "" || len(items) == 0 {
+		return false
+	}
+	start := 0
+	if fullPos := f.cursorFullPosition(); fullPos < len(f.positions) {
+		for index := range items {
+			if items[index] == f.positions[fullPos] {
+				start = index
+				break
 			}
 		}
 	}
-	f.positions = positions
+
+	step := 1
+	if !forward {
+		step = -1
+	}
+	if !includeCurrent {
+		start += step
+	}
+	for count := 0; count < len(items); count++ {
+		item := items[((start+count*step)%len(items)+len(items))%len(items)]
+		if itemName(item) == "" || !nameMatches(item, query) {
+			continue
+		}
+		expandAncestors(item)
+		f.refresh(s)
+		f.selectItem(s, item)
+		return true
+	}
+	return false
+}
+
+// selectItem moves cursor to the item, scrolling only when it is not visible already.
+func (f *InfoFrame) selectItem(s tcell.Screen, item Item) {
+	for index := range f.positions {
+		if f.positions[index] == item {
+			f.selectPosition(s, index)
+			return
+		}
+	}
+}
+
+func (f *InfoFrame) selectPosition(s tcell.Screen, index int) {
+	if index < f.scrollYOffset || index >= f.scrollYOffset+f.height {
+		f.scrollYOffset = index - f.height/2
+		if f.scrollYOffset < 0 {
+			f.scrollYOffset = 0
+		}
+	}
+	f.cursorY = index - f.scrollYOffset
+	f.refresh(s)
+}
+
+// setFilter narrows shown items to the ones with names containing the filter, empty filter shows everything.
+func (f *InfoFrame) setFilter(s tcell.Screen, filter string) {
+	f.filter = filter
+	f.cursorY = 0
+	f.scrollYOffset = 0
+	f.refresh(s)
+}
+
+func (f *InfoFrame) setPodSort(s tcell.Screen, ps podSort) {
+	f.podSort = ps
+	f.refresh(s)
+}
+
+func (f *InfoFrame) toggleProblemsOnly(s tcell.Screen) {
+	f.problemsOnly = !f.problemsOnly
+	f.cursorY = 0
+	f.scrollYOffset = 0
+	f.refresh(s)
+}
+
+func (f *InfoFrame) updateViewInfo(s tcell.Screen) {
+	values := make([]string, 0)
+	if f.problemsOnly {
+		values = append(values, fmt.Sprintf("Problems only, %v healthy items hidden (u to show all)", f.hiddenCount))
+	}
+	if f.filter != "" {
+		values = append(values, fmt.Sprintf("Filter: %v (Esc to clear)", f.filter))
+	}
+	f.viewInfo.UpdateS(s, strings.Join(values, "   "), tcell.StyleDefault.Foreground(tcell.ColorYellow))
 }
 
 func (f *InfoFrame) updatePodHeader(s tcell.Screen) {
@@ -116,6 +242,11 @@ func (f *InfoFrame) updatePodHeader(s tcell.Screen) {
 					f.nameColWidth = PodGroupXOffset + ColumnSpacing + len(f.nsItems[nsIndex].deployments[dIndex].name)
 				}
 				if f.nsItems[nsIndex].deployments[dIndex].isExpanded {
+					for _, revision := range f.nsItems[nsIndex].deployments[dIndex].revisions {
+						if f.nameColWidth < ColumnSpacing+len(revision.DisplayName()) {
+							f.nameColWidth = ColumnSpacing + len(revision.DisplayName())
+						}
+					}
 					for pIndex := range f.nsItems[nsIndex].deployments[dIndex].pods {
 						if f.nameColWidth < ColumnSpacing+len(f.nsItems[nsIndex].deployments[dIndex].pods[pIndex].name) {
 							f.nameColWidth = ColumnSpacing + len(f.nsItems[nsIndex].deployments[dIndex].pods[pIndex].name)
@@ -138,12 +269,27 @@ func (f *InfoFrame) updatePodHeader(s tcell.Screen) {
 		}
 	}
 
-	toPrint :=
-		"NAME" + strings.Repeat(" ", f.nameColWidth-4) +
-			"READY" + strings.Repeat(" ", f.readyColWidth-5) +
-			"STATUS" + strings.Repeat(" ", f.statusColWidth-6) +
-			"RESTARTS" + strings.Repeat(" ", f.restartsColWidth-8) +
-			"AGE"
+	columns := []struct {
+		label string
+		width int
+	}{
+		{"NAME", f.nameColWidth},
+		{"READY", f.readyColWidth},
+		{"STATUS", f.statusColWidth},
+		{"RESTARTS", f.restartsColWidth},
+		{"AGE", 0},
+	}
+	toPrint := ""
+	for index, column := range columns {
+		label := column.label
+		if podSortColumn(index) == f.podSort.column {
+			label += f.podSort.indicator()
+		}
+		if index < len(columns)-1 {
+			label += strings.Repeat(" ", column.width-len(label))
+		}
+		toPrint += label
+	}
 	f.podHeader.Update(s, toPrint)
 }
 
@@ -161,6 +307,8 @@ func (f *InfoFrame) updateFrameInfo(s tcell.Screen) {
 			f.printNamespaceError(s, position.(*NamespaceError), posIndex)
 		case TypePodGroup:
 			f.printPodGroup(s, position.(*PodGroup), posIndex)
+		case TypeRevision:
+			f.printRevision(s, position.(*Revision), posIndex)
 		case TypePod:
 			f.printPod(s, position.(*Pod), posIndex)
 		case TypeContainer:
@@ -179,7 +327,7 @@ func (f *InfoFrame) printNamespace(s tcell.Screen, ns *Namespace, yPos int) {
 			totalCount += len(ns.deployments[dIndex].pods)
 			readyCount += ns.deployments[dIndex].countReadyPods()
 		}
-		if readyCount != totalCount || ns.nsError.error != nil {
+		if !ns.isHealthy() {
 			style = style.Foreground(tcell.ColorRed)
 		}
 		readyColPos := f.nameColWidth - NamespaceXOffset + PodXOffset
@@ -204,27 +352,43 @@ func (f *InfoFrame) printPodGroup(s tcell.Screen, d *PodGroup, yPos int) {
 	if !d.isExpanded {
 		total := len(d.pods)
 		ready := d.countReadyPods()
-		if total != ready {
+		if !d.isHealthy() {
 			style = style.Foreground(tcell.ColorRed)
 		} else {
 			style = style.Foreground(tcell.ColorGreen)
 		}
 
 		readyColPos := f.nameColWidth - NamespaceXOffset + PodXOffset
+		statusColPos := readyColPos + f.readyColWidth
 		drawS(s, d.name, PodGroupXOffset, f.y+yPos, readyColPos, style)
-		drawS(s, fmt.Sprintf("%v/%v", ready, total), readyColPos, f.y+yPos, f.width-readyColPos, style)
+		drawS(s, fmt.Sprintf("%v/%v", ready, total), readyColPos, f.y+yPos, f.readyColWidth, style)
+		drawS(s, d.statusInfo(), statusColPos, f.y+yPos, f.width-statusColPos, style)
 	} else {
 		drawS(s, d.name, PodGroupXOffset, f.y+yPos, f.width, style)
 	}
 }
 
+func (f *InfoFrame) printRevision(s tcell.Screen, r *Revision, yPos int) {
+	style := tcell.StyleDefault.Bold(true)
+	status := "old"
+	if r.isCurrent {
+		status = "current"
+	}
+
+	xOffset := PodXOffset
+	drawS(s, r.DisplayName(), xOffset, f.y+yPos, f.nameColWidth, style)
+	xOffset += f.nameColWidth
+	drawS(s, fmt.Sprintf("%v/%v", r.countReadyPods(), len(r.pods)), xOffset, f.y+yPos, f.readyColWidth, style)
+	xOffset += f.readyColWidth
+	drawS(s, status, xOffset, f.y+yPos, f.width-xOffset, style)
+}
+
 func (f *InfoFrame) printPod(s tcell.Screen, p *Pod, yPos int) {
-	running := p.status == "Running"
 	style := tcell.StyleDefault
 	if !p.isExpanded {
-		if running && p.ready >= p.total {
+		if p.isHealthy() {
 			style = style.Foreground(tcell.ColorGreen)
-		} else if running && p.ready < p.total {
+		} else if p.isRunning() {
 			style = style.Foreground(tcell.ColorYellow)
 		} else {
 			style = style.Foreground(tcell.ColorRed)
@@ -252,29 +416,16 @@ func (f *InfoFrame) printContainer(s tcell.Screen, c *Container, yPos int) {
 	drawS(s, c.DisplayName(), ContainerXOffset, f.y+yPos, f.width-ContainerXOffset, style)
 }
 
-// updateNamespaces will get all expanded item names, apply expanded flag on new namespaces infos and replace existing f.nsItems with
-// new namespaces.
-// frame positions will need to be updated straight after to avoid errors.
+// updateNamespaces will remember expansion state of all items, replace existing f.nsItems with new namespaces and
+// apply the state to items with the same identity, new items keep their default state.
+// Cursor stays on the selected item, or on its closest ancestor when the item is gone.
 func (f *InfoFrame) updateNamespaces(podListResults []PodListResult) {
-	expanded := make(map[string]struct{}, 0)
-
-	for nsIndex, _ := range f.nsItems {
-		nsDisplayName := f.nsItems[nsIndex].DisplayName()
-		if f.nsItems[nsIndex].IsExpanded() {
-			expanded[nsDisplayName] = struct{}{}
-		}
-		for dIndex := range f.nsItems[nsIndex].deployments {
-			deploymentName := f.nsItems[nsIndex].deployments[dIndex].name
-			if f.nsItems[nsIndex].deployments[dIndex].isExpanded {
-				// Here we need a unique deployment name, therefore it is concatenated with namespace name.
-				expanded[nsDisplayName+deploymentName] = struct{}{}
-			}
-			for podIndex := range f.nsItems[nsIndex].deployments[dIndex].pods {
-				if f.nsItems[nsIndex].deployments[dIndex].pods[podIndex].IsExpanded() {
-					expanded[f.nsItems[nsIndex].deployments[dIndex].pods[podIndex].name] = struct{}{}
-				}
-			}
-		}
+	selection := f.selectionPath()
+	expanded := make(map[itemID]bool)
+	for nsIndex := range f.nsItems {
+		walkItems(&f.nsItems[nsIndex], func(item Item) {
+			expanded[identity(item)] = item.IsExpanded()
+		})
 	}
 
 	newNamespaces := make([]Namespace, len(podListResults))
@@ -292,27 +443,75 @@ func (f *InfoFrame) updateNamespaces(podListResults []PodListResult) {
 		return newNamespaces[i].name < newNamespaces[j].name
 	})
 
-	for nsIndex, _ := range newNamespaces {
-		nsDisplayName := newNamespaces[nsIndex].DisplayName()
-		_, ok := expanded[nsDisplayName]
-		if ok {
-			newNamespaces[nsIndex].isExpanded = true
-		}
-		for dIndex := range newNamespaces[nsIndex].deployments {
-			deploymentName := newNamespaces[nsIndex].deployments[dIndex].name
-			_, ok := expanded[nsDisplayName+deploymentName]
-			if ok {
-				newNamespaces[nsIndex].deployments[dIndex].Expanded(true)
+	for nsIndex := range newNamespaces {
+		newNamespaces[nsIndex].linkParents()
+		walkItems(&newNamespaces[nsIndex], func(item Item) {
+			if isExpanded, ok := expanded[identity(item)]; ok {
+				item.Expanded(isExpanded)
 			}
-			for podIndex := range newNamespaces[nsIndex].deployments[dIndex].pods {
-				_, ok := expanded[newNamespaces[nsIndex].deployments[dIndex].pods[podIndex].name]
-				if ok {
-					newNamespaces[nsIndex].deployments[dIndex].pods[podIndex].isExpanded = true
-				}
+		})
+		f.shells.apply(&newNamespaces[nsIndex])
+	}
+	f.nsItems = newNamespaces
+	f.updatePositions()
+	f.restoreSelection(selection)
+}
+
+// setShell caches shell of the container image and applies it to all containers of the image, including the given
+// container, which may be gone from the frame after a refresh.
+func (f *InfoFrame) setShell(c *Container, shell []string) {
+	f.Lock()
+	defer f.Unlock()
+	if f.shells == nil {
+		f.shells = make(shellCache)
+	}
+	f.shells[c.image] = shell
+	f.shells.apply(c)
+	for nsIndex := range f.nsItems {
+		f.shells.apply(&f.nsItems[nsIndex])
+	}
+}
+
+// containerShell returns the probed shell of the container, probed is false when it is not known yet.
+func (f *InfoFrame) containerShell(c *Container) (shell []string, probed bool) {
+	f.Lock()
+	defer f.Unlock()
+	return c.shell, c.shellProbed
+}
+
+// selectionPath returns identities of the selected item and its ancestors, starting from the item.
+func (f *InfoFrame) selectionPath() []itemID {
+	fullPos := f.cursorFullPosition()
+	if fullPos >= len(f.positions) {
+		return nil
+	}
+	path := make([]itemID, 0)
+	for item := f.positions[fullPos]; item != nil; item = parentOf(item) {
+		path = append(path, identity(item))
+	}
+	return path
+}
+
+// restoreSelection moves cursor to the first item of the path found in positions. Cursor keeps its row on the screen
+// when possible, the items are scrolled instead, unless it would leave empty rows at the bottom.
+func (f *InfoFrame) restoreSelection(path []itemID) {
+	for _, id := range path {
+		for index := range f.positions {
+			if identity(f.positions[index]) != id {
+				continue
+			}
+			offset := index - f.cursorY
+			if maxOffset := len(f.positions) - f.height; offset > maxOffset {
+				offset = maxOffset
+			}
+			if offset < 0 {
+				offset = 0
 			}
+			f.scrollYOffset = offset
+			f.cursorY = index - offset
+			return
 		}
 	}
-	f.nsItems = newNamespaces
 }
 
 func (f *InfoFrame) updateCursor(s tcell.Screen) {
@@ -422,6 +621,9 @@ func (f *InfoFrame) expandAll(s tcell.Screen) {
 		f.nsItems[nIndex].Expanded(true)
 		for dIndex := range f.nsItems[nIndex].deployments {
 			f.nsItems[nIndex].deployments[dIndex].Expanded(true)
+			for _, revision := range f.nsItems[nIndex].deployments[dIndex].revisions {
+				revision.Expanded(true)
+			}
 		}
 	}
 	f.refresh(s)
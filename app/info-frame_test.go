@@ -1,8 +1,12 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gdamore/tcell"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"reflect"
 	"strconv"
 	"testing"
 )
@@ -102,7 +106,8 @@ func TestMoveCursor(t *testing.T) {
 		},
 	}
 
-	for index, tc := range testTable {
+	for index := range testTable {
+		tc := &testTable[index]
 		t.Run(fmt.Sprintf("%v %v", index, tc.name), func(t *testing.T) {
 			tc.frame.updatePositions()
 			tc.frame.moveCursor(screen, tc.moveBy)
@@ -123,3 +128,236 @@ func fakeNamespaces(count int) []Namespace {
 	}
 	return ns
 }
+
+func TestFilterPositions(t *testing.T) {
+	testTable := []struct {
+		name          string
+		filter        string
+		expectedNames []string
+	}{
+		{
+			name:          "no_filter_keeps_expansion",
+			filter:        "",
+			expectedNames: []string{"a / context", "web", "web-1", "web-2", "db", "b / context"},
+		},
+		{
+			name:          "pod_match_shows_ancestors",
+			filter:        "WEB-2",
+			expectedNames: []string{"a / context", "web", "web-2"},
+		},
+		{
+			name:          "collapsed_ancestors_are_shown_expanded",
+			filter:        "api",
+			expectedNames: []string{"b / context", "api", "api-1"},
+		},
+		{
+			name:          "matching_group_keeps_its_expansion",
+			filter:        "db",
+			expectedNames: []string{"a / context", "db"},
+		},
+		{
+			name:          "no_match",
+			filter:        "nothing",
+			expectedNames: []string{},
+		},
+	}
+
+	for index := range testTable {
+		tc := &testTable[index]
+		t.Run(fmt.Sprintf("%v %v", index, tc.name), func(t *testing.T) {
+			frame := InfoFrame{height: 10, nsItems: fakeTree(), filter: tc.filter}
+			frame.updatePositions()
+			names := make([]string, 0)
+			for _, item := range frame.positions {
+				names = append(names, itemName(item))
+			}
+			if !reflect.DeepEqual(names, tc.expectedNames) {
+				t.Errorf("Invalid positions.\nWant: %q\nGot:  %q", tc.expectedNames, names)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	frame := InfoFrame{height: 10, nsItems: fakeTree()}
+	frame.updatePositions()
+
+	if !frame.search(screen, "api", true, true) {
+		t.Fatal("Expected to find 'api'")
+	}
+	if name := itemName(frame.positions[frame.cursorFullPosition()]); name != "api" {
+		t.Errorf("Invalid selected item. Want: api, Got: %v", name)
+	}
+	if !frame.nsItems[1].isExpanded {
+		t.Errorf("Namespace of found item should be expanded")
+	}
+
+	frame.search(screen, "api", true, false)
+	if name := itemName(frame.positions[frame.cursorFullPosition()]); name != "api-1" {
+		t.Errorf("Invalid next item. Want: api-1, Got: %v", name)
+	}
+	frame.search(screen, "api", true, false)
+	if name := itemName(frame.positions[frame.cursorFullPosition()]); name != "api" {
+		t.Errorf("Search should wrap around. Want: api, Got: %v", name)
+	}
+	frame.search(screen, "web", false, false)
+	if name := itemName(frame.positions[frame.cursorFullPosition()]); name != "web-2" {
+		t.Errorf("Invalid previous item. Want: web-2, Got: %v", name)
+	}
+	if frame.search(screen, "nothing", true, false) {
+		t.Errorf("Expected not to find 'nothing'")
+	}
+}
+
+// fakeTree returns namespace 'a' expanded with groups 'web' (expanded) and 'db', and collapsed namespace 'b' with
+// group 'api'.
+func fakeTree() []Namespace {
+	namespaces := []Namespace{
+		{name: "a", context: "context", isExpanded: true, deployments: []*PodGroup{
+			{name: "web", isExpanded: true, pods: []Pod{{name: "web-1"}, {name: "web-2"}}},
+			{name: "db", pods: []Pod{{name: "postgres-0"}}},
+		}},
+		{name: "b", context: "context", deployments: []*PodGroup{
+			{name: "api", pods: []Pod{{name: "api-1"}}},
+		}},
+	}
+	for nsIndex := range namespaces {
+		namespaces[nsIndex].linkParents()
+		for _, pg := range namespaces[nsIndex].deployments {
+			for pIndex := range pg.pods {
+				pg.pods[pIndex].podGroup = pg
+			}
+		}
+	}
+	return namespaces
+}
+
+func TestProblemsOnlyPositions(t *testing.T) {
+	namespaces := []Namespace{
+		{name: "a", context: "context", deployments: []*PodGroup{
+			{name: "web", pods: []Pod{
+				{name: "web-1", status: "Running", ready: 1, total: 1, containers: []Container{{name: "web", ready: true}}},
+				{name: "web-2", status: "CrashLoopBackOff", ready: 1, total: 2, containers: []Container{
+					{name: "web", ready: true},
+					{name: "sidecar", ready: false},
+				}},
+			}},
+			{name: "db", pods: []Pod{{name: "db-0", status: "Running", ready: 1, total: 1}}},
+		}},
+		{name: "b", context: "context", deployments: []*PodGroup{
+			{name: "api", pods: []Pod{{name: "api-1", status: "Running", ready: 1, total: 1}}},
+		}},
+		{name: "c", context: "context", nsError: NamespaceError{error: errors.New("forbidden")}},
+	}
+	for nsIndex := range namespaces {
+		namespaces[nsIndex].linkParents()
+	}
+
+	frame := InfoFrame{height: 10, nsItems: namespaces, problemsOnly: true}
+	frame.updatePositions()
+	names := make([]string, 0)
+	for _, item := range frame.positions {
+		names = append(names, itemName(item))
+	}
+	expected := []string{"a / context", "web", "web-2", "sidecar", "c / context", ""}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Invalid positions.\nWant: %q\nGot:  %q", expected, names)
+	}
+	// web-1 with its container, web container of web-2, db with its pod and b with its group and pod.
+	if frame.hiddenCount != 8 {
+		t.Errorf("Invalid hidden count. Want: 8, Got: %v", frame.hiddenCount)
+	}
+}
+
+func TestUpdateNamespacesKeepsExpansion(t *testing.T) {
+	strategies, _ := parseGroupStrategies(defaultGroupBy(DefaultPodGroupLabels))
+	podList := func(context, podUID string) PodListResult {
+		pod := v1.Pod{ObjectMeta: fakeMeta("web-1", fakeOwner(KindStatefulSet, "web"))}
+		pod.UID = types.UID(podUID)
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{{Name: "app"}}
+		return PodListResult{
+			context:         context,
+			namespace:       "ns",
+			groupStrategies: strategies,
+			PodList:         v1.PodList{Items: []v1.Pod{pod}},
+		}
+	}
+	findPod := func(frame *InfoFrame, context string) *Pod {
+		for nsIndex := range frame.nsItems {
+			if frame.nsItems[nsIndex].context == context {
+				return &frame.nsItems[nsIndex].deployments[0].pods[0]
+			}
+		}
+		return nil
+	}
+
+	frame := InfoFrame{height: 10}
+	frame.updateNamespaces([]PodListResult{podList("dev", "dev-uid"), podList("stage", "stage-uid")})
+	findPod(&frame, "dev").Expanded(true)
+	findPod(&frame, "dev").podGroup.Expanded(true)
+
+	frame.updateNamespaces([]PodListResult{podList("dev", "dev-uid"), podList("stage", "stage-uid")})
+	if !findPod(&frame, "dev").isExpanded || !findPod(&frame, "dev").podGroup.isExpanded {
+		t.Errorf("Pod and its group should stay expanded")
+	}
+	if findPod(&frame, "stage").isExpanded || findPod(&frame, "stage").podGroup.isExpanded {
+		t.Errorf("Identically named pod in another context should not be expanded")
+	}
+
+	frame.updateNamespaces([]PodListResult{podList("dev", "recreated-uid"), podList("stage", "stage-uid")})
+	if findPod(&frame, "dev").isExpanded {
+		t.Errorf("Recreated pod should not keep expansion state")
+	}
+	if !findPod(&frame, "dev").podGroup.isExpanded {
+		t.Errorf("Pod group should stay expanded when its pod is recreated")
+	}
+}
+
+func TestCursorFollowsSelectedItem(t *testing.T) {
+	strategies, _ := parseGroupStrategies(defaultGroupBy(DefaultPodGroupLabels))
+	podList := func(podNames ...string) PodListResult {
+		pods := make([]v1.Pod, 0)
+		for _, name := range podNames {
+			pod := v1.Pod{ObjectMeta: fakeMeta(name, fakeOwner(KindStatefulSet, "web"))}
+			pod.UID = types.UID(name + "-uid")
+			pods = append(pods, pod)
+		}
+		return PodListResult{context: "dev", namespace: "ns", groupStrategies: strategies, PodList: v1.PodList{Items: pods}}
+	}
+	selected := func(frame *InfoFrame) string {
+		return itemName(frame.positions[frame.cursorFullPosition()])
+	}
+
+	frame := InfoFrame{height: 10}
+	frame.updateNamespaces([]PodListResult{podList("web-2", "web-3")})
+	frame.nsItems[0].Expanded(true)
+	frame.nsItems[0].deployments[0].Expanded(true)
+	frame.updatePositions()
+	// namespace, group, web-2, web-3
+	frame.cursorY = 3
+	if selected(&frame) != "web-3" {
+		t.Fatalf("Invalid initial selection: %v", selected(&frame))
+	}
+
+	frame.updateNamespaces([]PodListResult{podList("web-1", "web-2", "web-3")})
+	if selected(&frame) != "web-3" {
+		t.Errorf("Cursor should follow selected pod. Want: web-3, Got: %v", selected(&frame))
+	}
+	if frame.cursorY != 4 || frame.scrollYOffset != 0 {
+		t.Errorf("Items fitting the screen should not be scrolled. Want: 4/0, Got: %v/%v", frame.cursorY, frame.scrollYOffset)
+	}
+
+	frame.height = 3
+	frame.cursorY, frame.scrollYOffset = 1, 3
+	frame.updateNamespaces([]PodListResult{podList("web-0", "web-1", "web-2", "web-3", "web-4")})
+	if selected(&frame) != "web-3" || frame.cursorY != 1 || frame.scrollYOffset != 4 {
+		t.Errorf("Cursor should keep its row. Want: web-3 1/4, Got: %v %v/%v", selected(&frame), frame.cursorY, frame.scrollYOffset)
+	}
+	frame.height = 10
+
+	frame.updateNamespaces([]PodListResult{podList("web-1", "web-2")})
+	if selected(&frame) != "web" {
+		t.Errorf("Cursor should fall back to parent. Want: web, Got: %v", selected(&frame))
+	}
+}
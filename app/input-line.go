@@ -0,0 +1,43 @@
+package app
+
+import "github.com/gdamore/tcell"
+
+// inputLine is a single line text input shown in the status bar, while it is active it receives all key events.
+type inputLine struct {
+	prompt string
+	value  string
+	// onChange is called after every edit, it can be nil.
+	onChange func(value string)
+	// onDone is called when input is confirmed with Enter or cancelled with Esc.
+	onDone func(value string, confirmed bool)
+}
+
+// handleKey applies key event to the input and returns true when input is finished.
+func (il *inputLine) handleKey(ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		il.onDone(il.value, true)
+		return true
+	case tcell.KeyEscape:
+		il.onDone(il.value, false)
+		return true
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(il.value) == 0 {
+			return false
+		}
+		runes := []rune(il.value)
+		il.value = string(runes[:len(runes)-1])
+	case tcell.KeyRune:
+		il.value += string(ev.Rune())
+	default:
+		return false
+	}
+	if il.onChange != nil {
+		il.onChange(il.value)
+	}
+	return false
+}
+
+func (il *inputLine) String() string {
+	return il.prompt + il.value
+}
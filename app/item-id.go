@@ -0,0 +1,113 @@
+package app
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// itemID identifies an item across refreshes, so that per item state like expansion or cursor position survives them.
+// Items without a Kubernetes UID, e.g. containers or pod groups named after a label, use a name which is unique
+// within their parent instead. Cluster includes the kubeconfig, as the same context name can come from different
+// kubeconfigs.
+type itemID struct {
+	cluster   clusterKey
+	namespace string
+	kind      string
+	uid       string
+}
+
+func identity(item Item) itemID {
+	ns := namespaceOf(item)
+	if ns == nil {
+		return itemID{kind: kindOf(item), uid: uidOf(item)}
+	}
+	return itemID{cluster: ns.cluster(), namespace: ns.name, kind: kindOf(item), uid: uidOf(item)}
+}
+
+func kindOf(item Item) string {
+	switch item.Type() {
+	case TypeNamespace:
+		return "Namespace"
+	case TypePodGroup:
+		return "PodGroup"
+	case TypeRevision:
+		return "Revision"
+	case TypePod:
+		return "Pod"
+	case TypeContainer:
+		return "Container"
+	case TypeNamespaceError:
+		return "NamespaceError"
+	case TypeNamespaceMessage:
+		return "NamespaceMessage"
+	}
+	return ""
+}
+
+func uidOf(item Item) string {
+	switch item.Type() {
+	case TypePodGroup:
+		pg := item.(*PodGroup)
+		if pg.uid != "" {
+			return string(pg.uid)
+		}
+		return pg.kind + "/" + pg.name
+	case TypeRevision:
+		revision := item.(*Revision)
+		return uidOf(revision.podGroup) + "/" + revision.hash
+	case TypePod:
+		pod := item.(*Pod)
+		if pod.uid != "" {
+			return string(pod.uid)
+		}
+		return pod.name
+	case TypeContainer:
+		c := item.(*Container)
+		return uidOf(c.pod) + "/" + c.name
+	}
+	return ""
+}
+
+func namespaceOf(item Item) *Namespace {
+	for ; item != nil; item = parentOf(item) {
+		if ns, ok := item.(*Namespace); ok {
+			return ns
+		}
+	}
+	return nil
+}
+
+// walkItems calls fn for item and all of its descendants.
+func walkItems(item Item, fn func(Item)) {
+	fn(item)
+	for _, child := range children(item) {
+		walkItems(child, fn)
+	}
+}
+
+// applyOwnerUIDs sets UIDs of pod groups owned by a listed controller.
+func applyOwnerUIDs(podGroups []*PodGroup, plr *PodListResult) {
+	uids := make(map[string]types.UID)
+	for _, rs := range plr.replicaSets {
+		uids[KindReplicaSet+"/"+rs.Name] = rs.UID
+	}
+	for _, job := range plr.jobs {
+		uids[KindJob+"/"+job.Name] = job.UID
+	}
+	for _, cronJob := range plr.cronJobs {
+		uids[KindCronJob+"/"+cronJob.Name] = cronJob.UID
+	}
+	for _, deployment := range plr.deployments {
+		uids[KindDeployment+"/"+deployment.Name] = deployment.UID
+	}
+	for _, statefulSet := range plr.statefulSets {
+		uids[KindStatefulSet+"/"+statefulSet.Name] = statefulSet.UID
+	}
+	for _, daemonSet := range plr.daemonSets {
+		uids[KindDaemonSet+"/"+daemonSet.Name] = daemonSet.UID
+	}
+	for _, pg := range podGroups {
+		if pg.kind != "" {
+			pg.uid = uids[pg.kind+"/"+pg.name]
+		}
+	}
+}
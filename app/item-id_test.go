@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIdentity(t *testing.T) {
+	namespaces := []Namespace{
+		{name: "ns", context: "dev", deployments: []*PodGroup{
+			{name: "web", kind: KindDeployment, uid: "web-uid", pods: []Pod{{name: "web-1", uid: "pod-uid", containers: []Container{{name: "app"}}}}},
+			{name: "legacy", pods: []Pod{{name: "legacy-1"}}},
+		}},
+	}
+	namespaces[0].linkParents()
+	for _, pg := range namespaces[0].deployments {
+		for pIndex := range pg.pods {
+			pg.pods[pIndex].podGroup = pg
+		}
+	}
+	web := namespaces[0].deployments[0]
+	dev := clusterKey{context: "dev"}
+	revision := &Revision{hash: "abc", podGroup: web}
+
+	testTable := []struct {
+		name     string
+		item     Item
+		expected itemID
+	}{
+		{"namespace", &namespaces[0], itemID{dev, "ns", "Namespace", ""}},
+		{"pod_group_with_uid", web, itemID{dev, "ns", "PodGroup", "web-uid"}},
+		{"pod_group_without_uid", namespaces[0].deployments[1], itemID{dev, "ns", "PodGroup", "/legacy"}},
+		{"revision", revision, itemID{dev, "ns", "Revision", "web-uid/abc"}},
+		{"pod", &web.pods[0], itemID{dev, "ns", "Pod", "pod-uid"}},
+		{"pod_without_uid", &namespaces[0].deployments[1].pods[0], itemID{dev, "ns", "Pod", "legacy-1"}},
+		{"container", &web.pods[0].containers[0], itemID{dev, "ns", "Container", "pod-uid/app"}},
+	}
+
+	for index := range testTable {
+		tc := &testTable[index]
+		t.Run(fmt.Sprintf("%v %v", index, tc.name), func(t *testing.T) {
+			if id := identity(tc.item); id != tc.expected {
+				t.Errorf("Invalid identity. Want: %+v, Got: %+v", tc.expected, id)
+			}
+		})
+	}
+
+	other := Namespace{name: "ns", context: "dev", kubeconfig: "/home/user/.kube/other"}
+	if identity(&other) == identity(&namespaces[0]) {
+		t.Errorf("Namespaces of the same context name from different kubeconfigs should have different identities")
+	}
+}
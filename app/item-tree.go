@@ -0,0 +1,131 @@
+package app
+
+import "strings"
+
+// children returns child items in the order they are shown, regardless of item being expanded.
+func children(item Item) []Item {
+	items := make([]Item, 0)
+	switch item.Type() {
+	case TypeNamespace:
+		ns := item.(*Namespace)
+		if ns.nsMessage.message != "" {
+			items = append(items, &ns.nsMessage)
+		}
+		if ns.nsError.error != nil {
+			items = append(items, &ns.nsError)
+		}
+		for _, pg := range ns.deployments {
+			items = append(items, pg)
+		}
+	case TypePodGroup:
+		pg := item.(*PodGroup)
+		if len(pg.revisions) > 0 {
+			for _, revision := range pg.revisions {
+				items = append(items, revision)
+			}
+			break
+		}
+		for index := range pg.pods {
+			items = append(items, &pg.pods[index])
+		}
+	case TypeRevision:
+		for _, pod := range item.(*Revision).pods {
+			items = append(items, pod)
+		}
+	case TypePod:
+		pod := item.(*Pod)
+		for index := range pod.containers {
+			items = append(items, &pod.containers[index])
+		}
+	}
+	return items
+}
+
+// parentOf returns item's parent, nil for namespaces.
+func parentOf(item Item) Item {
+	switch item.Type() {
+	case TypePodGroup:
+		return item.(*PodGroup).namespace
+	case TypeRevision:
+		return item.(*Revision).podGroup
+	case TypePod:
+		pod := item.(*Pod)
+		if pod.revision != nil {
+			return pod.revision
+		}
+		return pod.podGroup
+	case TypeContainer:
+		return item.(*Container).pod
+	case TypeNamespaceError:
+		return item.(*NamespaceError).namespace
+	case TypeNamespaceMessage:
+		return item.(*NamespaceMessage).namespace
+	}
+	return nil
+}
+
+// itemName is the name used to search and filter items, messages and errors have none.
+func itemName(item Item) string {
+	switch item.Type() {
+	case TypeNamespace:
+		return item.(*Namespace).DisplayName()
+	case TypePodGroup:
+		return item.(*PodGroup).name
+	case TypeRevision:
+		return item.(*Revision).DisplayName()
+	case TypePod:
+		return item.(*Pod).name
+	case TypeContainer:
+		return item.(*Container).name
+	}
+	return ""
+}
+
+// nameMatches does case insensitive substring match of item name, empty query matches every item.
+func nameMatches(item Item, query string) bool {
+	if query == "" {
+		return true
+	}
+	name := itemName(item)
+	return name != "" && strings.Contains(strings.ToLower(name), strings.ToLower(query))
+}
+
+// isItemHealthy uses the same rules as item colouring in the main frame, errors are never healthy and messages always.
+func isItemHealthy(item Item) bool {
+	switch item.Type() {
+	case TypeNamespace:
+		return item.(*Namespace).isHealthy()
+	case TypePodGroup:
+		return item.(*PodGroup).isHealthy()
+	case TypeRevision:
+		for _, pod := range item.(*Revision).pods {
+			if !pod.isHealthy() {
+				return false
+			}
+		}
+		return true
+	case TypePod:
+		return item.(*Pod).isHealthy()
+	case TypeContainer:
+		c := item.(*Container)
+		return c.ready || c.pod.isCompleted()
+	case TypeNamespaceError:
+		return false
+	}
+	return true
+}
+
+// countItems counts item and all of its descendants.
+func countItems(item Item) int {
+	count := 1
+	for _, child := range children(item) {
+		count += countItems(child)
+	}
+	return count
+}
+
+func expandAncestors(item Item) {
+	for parent := parentOf(item); parent != nil; parent = parentOf(parent) {
+		parent.Expanded(true)
+	}
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"fmt"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"time"
+)
+
+// Default backoff limit of a Job when it is not set in spec.
+const defaultBackoffLimit = 6
+
+type jobStatus struct {
+	name         string
+	creationTime time.Time
+	completions  int32
+	succeeded    int32
+	failed       int32
+	active       int32
+	backoffLimit int32
+	isComplete   bool
+	isFailed     bool
+}
+
+func toJobStatus(job *batchv1.Job) *jobStatus {
+	status := &jobStatus{
+		name:         job.Name,
+		creationTime: job.CreationTimestamp.Time,
+		completions:  1,
+		succeeded:    job.Status.Succeeded,
+		failed:       job.Status.Failed,
+		active:       job.Status.Active,
+		backoffLimit: defaultBackoffLimit,
+	}
+	if job.Spec.Completions != nil {
+		status.completions = *job.Spec.Completions
+	}
+	if job.Spec.BackoffLimit != nil {
+		status.backoffLimit = *job.Spec.BackoffLimit
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			status.isComplete = true
+		case batchv1.JobFailed:
+			status.isFailed = true
+		}
+	}
+	return status
+}
+
+func (js *jobStatus) String() string {
+	switch {
+	case js.isComplete:
+		return fmt.Sprintf("Complete %d/%d", js.succeeded, js.completions)
+	case js.isFailed:
+		return fmt.Sprintf("Failed, backoff %d/%d", js.failed, js.backoffLimit)
+	case js.failed > 0:
+		return fmt.Sprintf("Running %d/%d, active %d, backoff %d/%d", js.succeeded, js.completions, js.active, js.failed, js.backoffLimit)
+	default:
+		return fmt.Sprintf("Running %d/%d, active %d", js.succeeded, js.completions, js.active)
+	}
+}
+
+type cronJobStatus struct {
+	suspended        bool
+	active           int
+	lastScheduleTime time.Time
+	// lastJob is the most recently created Job of the CronJob, nil when there is none.
+	lastJob *jobStatus
+}
+
+func toCronJobStatus(cronJob *batchv1beta1.CronJob, jobs []batchv1.Job) *cronJobStatus {
+	status := &cronJobStatus{
+		suspended: cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+		active:    len(cronJob.Status.Active),
+	}
+	if cronJob.Status.LastScheduleTime != nil {
+		status.lastScheduleTime = cronJob.Status.LastScheduleTime.Time
+	}
+	for index := range jobs {
+		ref := metav1.GetControllerOf(&jobs[index])
+		if ref == nil || ref.Kind != KindCronJob || ref.Name != cronJob.Name {
+			continue
+		}
+		if status.lastJob == nil || jobs[index].CreationTimestamp.Time.After(status.lastJob.creationTime) {
+			status.lastJob = toJobStatus(&jobs[index])
+		}
+	}
+	return status
+}
+
+func (cjs *cronJobStatus) String() string {
+	parts := make([]string, 0)
+	if cjs.suspended {
+		parts = append(parts, "Suspended")
+	}
+	if cjs.lastScheduleTime.IsZero() {
+		parts = append(parts, "never scheduled")
+	} else {
+		parts = append(parts, fmt.Sprintf("last schedule %v ago", translateTimestampSince(cjs.lastScheduleTime)))
+	}
+	parts = append(parts, fmt.Sprintf("active %d", cjs.active))
+	if cjs.lastJob != nil && cjs.lastJob.isFailed {
+		parts = append(parts, fmt.Sprintf("last job %v", cjs.lastJob))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// applyJobStatuses adds Job and CronJob statuses to pod groups owned by them.
+func applyJobStatuses(podGroups []*PodGroup, plr *PodListResult) {
+	jobs := make(map[string]*batchv1.Job)
+	for index := range plr.jobs {
+		jobs[plr.jobs[index].Name] = &plr.jobs[index]
+	}
+	cronJobs := make(map[string]*batchv1beta1.CronJob)
+	for index := range plr.cronJobs {
+		cronJobs[plr.cronJobs[index].Name] = &plr.cronJobs[index]
+	}
+
+	for _, pg := range podGroups {
+		switch pg.kind {
+		case KindJob:
+			if job, ok := jobs[pg.name]; ok {
+				pg.job = toJobStatus(job)
+			}
+		case KindCronJob:
+			if cronJob, ok := cronJobs[pg.name]; ok {
+				pg.cronJob = toCronJobStatus(cronJob, plr.jobs)
+			}
+		}
+	}
+}
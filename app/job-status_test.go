@@ -0,0 +1,99 @@
+package app
+
+import (
+	"fmt"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestJobStatusString(t *testing.T) {
+	three := int32(3)
+	testTable := []struct {
+		name     string
+		job      batchv1.Job
+		expected string
+	}{
+		{
+			name:     "complete",
+			job:      fakeJob("a", time.Time{}, batchv1.JobComplete, 1, 0, 0),
+			expected: "Complete 1/1",
+		},
+		{
+			name:     "failed_with_default_backoff",
+			job:      fakeJob("a", time.Time{}, batchv1.JobFailed, 0, 7, 0),
+			expected: "Failed, backoff 7/6",
+		},
+		{
+			name: "running_with_failures",
+			job: func() batchv1.Job {
+				job := fakeJob("a", time.Time{}, "", 0, 2, 1)
+				job.Spec.BackoffLimit = &three
+				return job
+			}(),
+			expected: "Running 0/1, active 1, backoff 2/3",
+		},
+		{
+			name:     "running",
+			job:      fakeJob("a", time.Time{}, "", 0, 0, 1),
+			expected: "Running 0/1, active 1",
+		},
+	}
+
+	for index := range testTable {
+		tc := &testTable[index]
+		t.Run(fmt.Sprintf("%v %v", index, tc.name), func(t *testing.T) {
+			status := toJobStatus(&tc.job).String()
+			if status != tc.expected {
+				t.Errorf("Invalid status. Want: %v, Got: %v", tc.expected, status)
+			}
+		})
+	}
+}
+
+func TestCronJobLastJob(t *testing.T) {
+	now := time.Now()
+	jobs := []batchv1.Job{
+		fakeJob("backup-2", now.Add(-time.Hour), batchv1.JobFailed, 0, 6, 0),
+		fakeJob("backup-1", now.Add(-2*time.Hour), batchv1.JobComplete, 1, 0, 0),
+		fakeJob("other-1", now, batchv1.JobComplete, 1, 0, 0),
+	}
+	jobs[0].OwnerReferences = []metav1.OwnerReference{fakeOwner(KindCronJob, "backup")}
+	jobs[1].OwnerReferences = []metav1.OwnerReference{fakeOwner(KindCronJob, "backup")}
+	jobs[2].OwnerReferences = []metav1.OwnerReference{fakeOwner(KindCronJob, "other")}
+
+	cronJob := batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup"},
+		Status: batchv1beta1.CronJobStatus{
+			Active:           []v1.ObjectReference{{Name: "backup-3"}},
+			LastScheduleTime: &metav1.Time{Time: now.Add(-5 * time.Minute)},
+		},
+	}
+
+	status := toCronJobStatus(&cronJob, jobs)
+	if status.lastJob == nil || status.lastJob.name != "backup-2" {
+		t.Fatalf("Invalid last job. Want: backup-2, Got: %+v", status.lastJob)
+	}
+	expected := "last schedule 5m ago, active 1, last job Failed, backoff 6/6"
+	if status.String() != expected {
+		t.Errorf("Invalid status. Want: %v, Got: %v", expected, status.String())
+	}
+	pg := PodGroup{kind: KindCronJob, cronJob: status}
+	if pg.isHealthy() {
+		t.Errorf("CronJob with failed last job should not be healthy")
+	}
+}
+
+func fakeJob(name string, created time.Time, condition batchv1.JobConditionType, succeeded, failed, active int32) batchv1.Job {
+	job := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.Time{Time: created}},
+		Status:     batchv1.JobStatus{Succeeded: succeeded, Failed: failed, Active: active},
+	}
+	if condition != "" {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: v1.ConditionTrue}}
+	}
+	return job
+}
@@ -0,0 +1,288 @@
+package app
+
+import (
+	"flag"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	"sync"
+	"time"
+)
+
+const (
+	cacheSyncTimeout    = 10 * time.Second
+	cacheRetryInterval  = 30 * time.Second
+	cacheRefreshTimeout = 30 * time.Second
+	cacheDebounce       = 200 * time.Millisecond
+)
+
+// podCache keeps a list+watch backed pod cache for every context/namespace pair and signals on notifyCh whenever
+// anything in them changes.
+type podCache struct {
+	sync.Mutex
+	entries  map[podCacheKey]*podCacheEntry
+	notifyCh chan struct{}
+}
+
+type podCacheEntry struct {
+	pods cache.SharedIndexInformer
+	// others are informers of owners and controllers which could be listed, their objects are sorted by type into
+	// PodListResult.
+	others []cache.SharedIndexInformer
+	// ownerErrors are errors of owners and controllers which couldn't be listed and aren't watched.
+	ownerErrors []error
+	stopCh      chan struct{}
+	err         error
+	startTime   time.Time
+}
+
+func newPodCache() *podCache {
+	silenceKlog()
+	return &podCache{
+		entries:  make(map[podCacheKey]*podCacheEntry),
+		notifyCh: make(chan struct{}, 1),
+	}
+}
+
+// podLists starts informers for namespaces which are not watched yet (or failed previously) and returns the current
+// content of the caches.
+func (pc *podCache) podLists(k8Client Client, group Group) []PodListResult {
+	jobs := podJobs(group)
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		if pc.needsStart(job) {
+			wg.Add(1)
+			go func(job getPodJob) {
+				pc.start(k8Client, job)
+				wg.Done()
+			}(job)
+		}
+	}
+	wg.Wait()
+
+	timeoutCh := make(chan struct{})
+	timer := time.AfterFunc(cacheSyncTimeout, func() { close(timeoutCh) })
+	defer timer.Stop()
+
+	podListResults := make([]PodListResult, 0, len(jobs))
+	for _, job := range jobs {
+		podListResults = append(podListResults, pc.podList(job, timeoutCh))
+	}
+	return podListResults
+}
+
+func (pc *podCache) needsStart(job getPodJob) bool {
+	pc.Lock()
+	defer pc.Unlock()
+	entry, ok := pc.entries[job.podCacheKey]
+	return !ok || (entry.err != nil && time.Since(entry.startTime) > cacheRetryInterval)
+}
+
+func (pc *podCache) start(k8Client Client, job getPodJob) {
+	entry := &podCacheEntry{startTime: time.Now()}
+	clientSet := k8Client.k8ClientSets[job.cluster()]
+
+	// Informers keep retrying silently on errors, so do a cheap list first to be able to report e.g. missing permissions.
+	probeOptions := job.listOptions()
+	probeOptions.Limit = 1
+	_, err := clientSet.CoreV1().Pods(job.namespace).List(probeOptions)
+	if err != nil {
+		entry.err = err
+		pc.set(job, entry)
+		return
+	}
+
+	entry.stopCh = make(chan struct{})
+	podFactory := informers.NewSharedInformerFactoryWithOptions(clientSet, 0,
+		informers.WithNamespace(job.namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = job.labelSelector
+			options.FieldSelector = job.fieldSelector
+		}))
+	entry.pods = pc.watch(podFactory.Core().V1().Pods().Informer())
+	podFactory.Start(entry.stopCh)
+
+	// Selectors are meant for pods only, so owners have their own unfiltered factory.
+	ownerFactory := informers.NewSharedInformerFactoryWithOptions(clientSet, 0, informers.WithNamespace(job.namespace))
+	// Controllers are listed to show their replica counts, the label selector limits them to the selected applications.
+	controllerFactory := informers.NewSharedInformerFactoryWithOptions(clientSet, 0,
+		informers.WithNamespace(job.namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = job.labelSelector
+		}))
+	probes := []struct {
+		list     func(metav1.ListOptions) error
+		informer func() cache.SharedIndexInformer
+	}{
+		{
+			list: func(o metav1.ListOptions) error {
+				_, err := clientSet.AppsV1().ReplicaSets(job.namespace).List(o)
+				return err
+			},
+			informer: ownerFactory.Apps().V1().ReplicaSets().Informer,
+		},
+		{
+			list: func(o metav1.ListOptions) error {
+				_, err := clientSet.BatchV1().Jobs(job.namespace).List(o)
+				return err
+			},
+			informer: ownerFactory.Batch().V1().Jobs().Informer,
+		},
+		{
+			list: func(o metav1.ListOptions) error {
+				_, err := clientSet.BatchV1beta1().CronJobs(job.namespace).List(o)
+				return err
+			},
+			informer: ownerFactory.Batch().V1beta1().CronJobs().Informer,
+		},
+		{
+			list: func(o metav1.ListOptions) error {
+				_, err := clientSet.AppsV1().Deployments(job.namespace).List(o)
+				return err
+			},
+			informer: controllerFactory.Apps().V1().Deployments().Informer,
+		},
+		{
+			list: func(o metav1.ListOptions) error {
+				_, err := clientSet.AppsV1().StatefulSets(job.namespace).List(o)
+				return err
+			},
+			informer: controllerFactory.Apps().V1().StatefulSets().Informer,
+		},
+		{
+			list: func(o metav1.ListOptions) error {
+				_, err := clientSet.AppsV1().DaemonSets(job.namespace).List(o)
+				return err
+			},
+			informer: controllerFactory.Apps().V1().DaemonSets().Informer,
+		},
+	}
+	for _, probe := range probes {
+		if err := probe.list(metav1.ListOptions{Limit: 1}); err != nil {
+			entry.ownerErrors = append(entry.ownerErrors, err)
+			continue
+		}
+		entry.others = append(entry.others, pc.watch(probe.informer()))
+	}
+	ownerFactory.Start(entry.stopCh)
+	controllerFactory.Start(entry.stopCh)
+
+	pc.set(job, entry)
+}
+
+func (pc *podCache) watch(informer cache.SharedIndexInformer) cache.SharedIndexInformer {
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { pc.notify() },
+		UpdateFunc: func(oldObj, newObj interface{}) { pc.notify() },
+		DeleteFunc: func(obj interface{}) { pc.notify() },
+	})
+	return informer
+}
+
+func (pc *podCache) set(job getPodJob, entry *podCacheEntry) {
+	pc.Lock()
+	defer pc.Unlock()
+	if previous, ok := pc.entries[job.podCacheKey]; ok && previous.stopCh != nil {
+		close(previous.stopCh)
+	}
+	pc.entries[job.podCacheKey] = entry
+}
+
+func (pc *podCache) podList(job getPodJob, timeoutCh <-chan struct{}) PodListResult {
+	pc.Lock()
+	entry := pc.entries[job.podCacheKey]
+	pc.Unlock()
+
+	result := job.newResult()
+	if entry.err != nil {
+		result.error = entry.err
+		return result
+	}
+	result.ownerErrors = entry.ownerErrors
+	if !cache.WaitForCacheSync(timeoutCh, entry.synced) {
+		result.error = errors.New("timed out waiting for pod cache to sync")
+		return result
+	}
+
+	for _, obj := range entry.pods.GetStore().List() {
+		if pod, ok := obj.(*v1.Pod); ok {
+			result.Items = append(result.Items, *pod)
+		}
+	}
+	for _, informer := range entry.others {
+		for _, obj := range informer.GetStore().List() {
+			switch o := obj.(type) {
+			case *appsv1.ReplicaSet:
+				result.replicaSets = append(result.replicaSets, *o)
+			case *batchv1.Job:
+				result.jobs = append(result.jobs, *o)
+			case *batchv1beta1.CronJob:
+				result.cronJobs = append(result.cronJobs, *o)
+			case *appsv1.Deployment:
+				result.deployments = append(result.deployments, *o)
+			case *appsv1.StatefulSet:
+				result.statefulSets = append(result.statefulSets, *o)
+			case *appsv1.DaemonSet:
+				result.daemonSets = append(result.daemonSets, *o)
+			}
+		}
+	}
+	return result
+}
+
+func (entry *podCacheEntry) synced() bool {
+	for _, informer := range append([]cache.SharedIndexInformer{entry.pods}, entry.others...) {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+func (pc *podCache) notify() {
+	select {
+	case pc.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// waitForChanges blocks until any of the caches changes, or until cacheRefreshTimeout passes to keep pod ages fresh.
+func (pc *podCache) waitForChanges() {
+	select {
+	case <-pc.notifyCh:
+		// Give a burst of events a moment to settle, so it results in a single refresh.
+		time.Sleep(cacheDebounce)
+		select {
+		case <-pc.notifyCh:
+		default:
+		}
+	case <-time.After(cacheRefreshTimeout):
+	}
+}
+
+func (pc *podCache) stop() {
+	pc.Lock()
+	defer pc.Unlock()
+	for key, entry := range pc.entries {
+		if entry.stopCh != nil {
+			close(entry.stopCh)
+		}
+		delete(pc.entries, key)
+	}
+}
+
+// silenceKlog stops client-go from printing reflector errors on top of the console ui.
+func silenceKlog() {
+	flags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(flags)
+	_ = flags.Set("logtostderr", "false")
+	klog.SetOutput(ioutil.Discard)
+}
@@ -3,63 +3,122 @@ package app
 import (
 	"fmt"
 	"github.com/pkg/errors"
+	"io"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 )
 
-type clientSetMap map[string]*kubernetes.Clientset
+const PollInterval = 5 * time.Second
+
+// clusterKey identifies a cluster, the same context name can point to different clusters in different kubeconfig files.
+type clusterKey struct {
+	kubeconfig string
+	context    string
+}
+
+type clientSetMap map[clusterKey]*kubernetes.Clientset
 
 type Client struct {
 	k8ClientSets clientSetMap
+	// k8Configs are kept for exec sessions, which are not using clientsets.
+	k8Configs map[clusterKey]*rest.Config
+	podCache  *podCache
+	// ownerCache is used when pods are polled instead of watched.
+	ownerCache *ownerCache
 }
 
 type K8Client interface {
 	podLists(group Group) []PodListResult
+	streamLogs(cluster clusterKey, namespace, pod string, options *v1.PodLogOptions) (io.ReadCloser, error)
+	exec(cluster clusterKey, namespace, pod string, options *v1.PodExecOptions, streams remotecommand.StreamOptions, stopCh <-chan struct{}) error
+	deleteResource(cluster clusterKey, namespace, kind, name string) error
+	scale(cluster clusterKey, namespace, kind, name string, replicas int32) error
+	waitForChanges()
+	stop()
 }
 
 type getPodJob struct {
-	context   string
-	namespace string
+	podCacheKey
+	groupStrategies []groupStrategy
+}
+
+// podCacheKey is everything which identifies a pod list request.
+type podCacheKey struct {
+	kubeconfig    string
+	context       string
+	namespace     string
+	labelSelector string
+	fieldSelector string
 }
 
 type PodListResult struct {
-	context   string
-	namespace string
+	kubeconfig      string
+	context         string
+	namespace       string
+	groupStrategies []groupStrategy
 	v1.PodList
+	// replicaSets, jobs and cronJobs are owners of pods, used to group pods and show job statuses.
+	replicaSets []appsv1.ReplicaSet
+	jobs        []batchv1.Job
+	cronJobs    []batchv1beta1.CronJob
+	// deployments, statefulSets and daemonSets are controllers, used to show replica counts.
+	deployments  []appsv1.Deployment
+	statefulSets []appsv1.StatefulSet
+	daemonSets   []appsv1.DaemonSet
+	// ownerErrors are errors of listing owners and controllers, which change grouping and replica counts of pods.
+	ownerErrors []error
 	error
 }
 
-func NewK8ClientSets(contexts map[string]struct{}) (Client, error) {
-	configPath := filepath.Join(os.Getenv("HOME"), ".kube", "config")
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return Client{}, errors.New("No config found in ~/.kube")
-	}
-
-	k8ClientSets := make(map[string]*kubernetes.Clientset)
-	for context := range contexts {
-		config, err := buildConfigFromFlags(context, configPath)
+// NewK8ClientSets creates a clientset for every cluster, cluster kubeconfig is an explicit kubeconfig path which can be
+// empty to use standard loading rules.
+func NewK8ClientSets(clusters []clusterKey) (Client, error) {
+	k8ClientSets := make(clientSetMap)
+	k8Configs := make(map[clusterKey]*rest.Config)
+	for _, cluster := range clusters {
+		if _, ok := k8ClientSets[cluster]; ok {
+			continue
+		}
+		config, err := buildConfigFromFlags(cluster.context, cluster.kubeconfig)
 		if err != nil {
-			return Client{}, errors.Wrapf(err, "Error creating client config for context: %v", context)
+			return Client{}, errors.Wrapf(err, "Error creating client config for context: %v", cluster.context)
 		}
 
 		k8client, err := kubernetes.NewForConfig(config)
 		if err != nil {
-			return Client{}, errors.Wrapf(err, "Error creating clientset for context: %v", context)
+			return Client{}, errors.Wrapf(err, "Error creating clientset for context: %v", cluster.context)
 		}
-		k8ClientSets[context] = k8client
+		k8ClientSets[cluster] = k8client
+		k8Configs[cluster] = config
 	}
 
-	return Client{k8ClientSets: k8ClientSets}, nil
+	return Client{k8ClientSets: k8ClientSets, k8Configs: k8Configs, ownerCache: newOwnerCache()}, nil
 }
 
+// podLists will return pods from the watch cache when it is enabled, otherwise every context/namespace pair is listed.
 func (k8Client Client) podLists(group Group) []PodListResult {
+	if k8Client.podCache != nil {
+		return k8Client.podCache.podLists(k8Client, group)
+	}
+
 	var wg sync.WaitGroup
 	resultCh := make(chan PodListResult)
 	jobCh := make(chan getPodJob)
@@ -71,13 +130,8 @@ func (k8Client Client) podLists(group Group) []PodListResult {
 	}
 
 	go func() {
-		for gIndex := range group.NsGroups {
-			for nsIndex := range group.NsGroups[gIndex].Namespaces {
-				jobCh <- getPodJob{
-					context:   group.NsGroups[gIndex].Context,
-					namespace: group.NsGroups[gIndex].Namespaces[nsIndex],
-				}
-			}
+		for _, job := range podJobs(group) {
+			jobCh <- job
 		}
 		close(jobCh)
 	}()
@@ -93,23 +147,196 @@ func (k8Client Client) podLists(group Group) []PodListResult {
 	return podListResults
 }
 
+// waitForChanges blocks until it is time to call podLists again.
+func (k8Client Client) waitForChanges() {
+	if k8Client.podCache != nil {
+		k8Client.podCache.waitForChanges()
+		return
+	}
+	time.Sleep(PollInterval)
+}
+
+// streamLogs opens a log stream of a pod container, it has to be closed by the caller.
+func (k8Client Client) streamLogs(cluster clusterKey, namespace, pod string, options *v1.PodLogOptions) (io.ReadCloser, error) {
+	return k8Client.k8ClientSets[cluster].CoreV1().Pods(namespace).GetLogs(pod, options).Stream()
+}
+
+// exec runs a command in a pod container over SPDY and blocks until it exits or stopCh is closed, streams are attached
+// to the command. Closing stopCh closes the connection, so the session ends even when the command doesn't read input.
+func (k8Client Client) exec(cluster clusterKey, namespace, pod string, options *v1.PodExecOptions, streams remotecommand.StreamOptions, stopCh <-chan struct{}) error {
+	req := k8Client.k8ClientSets[cluster].CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(options, scheme.ParameterCodec)
+	transport, upgrader, err := spdy.RoundTripperFor(k8Client.k8Configs[cluster])
+	if err != nil {
+		return err
+	}
+	executor, err := remotecommand.NewSPDYExecutorForTransports(transport, stoppableUpgrader{upgrader, stopCh}, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	return executor.Stream(streams)
+}
+
+// stoppableUpgrader closes the upgraded connection when stopCh is closed, client-go can't cancel a stream otherwise.
+type stoppableUpgrader struct {
+	spdy.Upgrader
+	stopCh <-chan struct{}
+}
+
+func (u stoppableUpgrader) NewConnection(resp *http.Response) (httpstream.Connection, error) {
+	conn, err := u.Upgrader.NewConnection(resp)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		select {
+		case <-u.stopCh:
+			_ = conn.Close()
+		case <-conn.CloseChan():
+		}
+	}()
+	return conn, nil
+}
+
+// deleteResource deletes a pod or a pod controller, dependents are deleted in the background like kubectl does.
+func (k8Client Client) deleteResource(cluster clusterKey, namespace, kind, name string) error {
+	propagation := metav1.DeletePropagationBackground
+	options := &metav1.DeleteOptions{PropagationPolicy: &propagation}
+	clientSet := k8Client.k8ClientSets[cluster]
+	switch kind {
+	case KindPod:
+		return clientSet.CoreV1().Pods(namespace).Delete(name, options)
+	case KindDeployment:
+		return clientSet.AppsV1().Deployments(namespace).Delete(name, options)
+	case KindStatefulSet:
+		return clientSet.AppsV1().StatefulSets(namespace).Delete(name, options)
+	case KindDaemonSet:
+		return clientSet.AppsV1().DaemonSets(namespace).Delete(name, options)
+	case KindReplicaSet:
+		return clientSet.AppsV1().ReplicaSets(namespace).Delete(name, options)
+	case KindJob:
+		return clientSet.BatchV1().Jobs(namespace).Delete(name, options)
+	case KindCronJob:
+		return clientSet.BatchV1beta1().CronJobs(namespace).Delete(name, options)
+	}
+	return fmt.Errorf("deleting %v is not supported", kind)
+}
+
+// scale updates replica count of a controller through its scale subresource.
+func (k8Client Client) scale(cluster clusterKey, namespace, kind, name string, replicas int32) error {
+	apps := k8Client.k8ClientSets[cluster].AppsV1()
+	var err error
+	var scale *autoscalingv1.Scale
+	switch kind {
+	case KindDeployment:
+		if scale, err = apps.Deployments(namespace).GetScale(name, metav1.GetOptions{}); err == nil {
+			scale.Spec.Replicas = replicas
+			_, err = apps.Deployments(namespace).UpdateScale(name, scale)
+		}
+	case KindStatefulSet:
+		if scale, err = apps.StatefulSets(namespace).GetScale(name, metav1.GetOptions{}); err == nil {
+			scale.Spec.Replicas = replicas
+			_, err = apps.StatefulSets(namespace).UpdateScale(name, scale)
+		}
+	case KindReplicaSet:
+		if scale, err = apps.ReplicaSets(namespace).GetScale(name, metav1.GetOptions{}); err == nil {
+			scale.Spec.Replicas = replicas
+			_, err = apps.ReplicaSets(namespace).UpdateScale(name, scale)
+		}
+	default:
+		return fmt.Errorf("scaling %v is not supported", kind)
+	}
+	return err
+}
+
+func (k8Client Client) stop() {
+	if k8Client.podCache != nil {
+		k8Client.podCache.stop()
+	}
+}
+
+func podJobs(group Group) []getPodJob {
+	jobs := make([]getPodJob, 0)
+	for gIndex := range group.NsGroups {
+		for nsIndex := range group.NsGroups[gIndex].Namespaces {
+			jobs = append(jobs, getPodJob{
+				podCacheKey: podCacheKey{
+					kubeconfig:    group.NsGroups[gIndex].Kubeconfig,
+					context:       group.NsGroups[gIndex].Context,
+					namespace:     group.NsGroups[gIndex].Namespaces[nsIndex],
+					labelSelector: group.NsGroups[gIndex].LabelSelector,
+					fieldSelector: group.NsGroups[gIndex].FieldSelector,
+				},
+				groupStrategies: group.NsGroups[gIndex].groupStrategies,
+			})
+		}
+	}
+	return jobs
+}
+
 func getPods(k8Client Client, jobCh <-chan getPodJob, resultCh chan<- PodListResult, wg *sync.WaitGroup) {
 	for job := range jobCh {
-		podList, err := k8Client.k8ClientSets[job.context].CoreV1().Pods(job.namespace).List(metav1.ListOptions{})
-		resultCh <- PodListResult{job.context, job.namespace, *podList, err}
+		resultCh <- listPods(k8Client.k8ClientSets[job.cluster()], k8Client.ownerCache, job)
 	}
 	wg.Done()
 }
 
-func (k8Client Client) listNamespaces(context string) (*v1.NamespaceList, error) {
-	fmt.Printf("Getting namespace list for context: %v \n", context)
-	return k8Client.k8ClientSets[context].CoreV1().Namespaces().List(metav1.ListOptions{})
+func listPods(clientSet *kubernetes.Clientset, owners *ownerCache, job getPodJob) PodListResult {
+	result := job.newResult()
+	podList, err := clientSet.CoreV1().Pods(job.namespace).List(job.listOptions())
+	if err != nil {
+		result.error = err
+		return result
+	}
+	result.PodList = *podList
+	owners.get(job.podCacheKey, func() ownerLists { return listOwners(clientSet, job) }).applyTo(&result)
+	return result
+}
+
+func (job getPodJob) newResult() PodListResult {
+	return PodListResult{
+		kubeconfig:      job.kubeconfig,
+		context:         job.context,
+		namespace:       job.namespace,
+		groupStrategies: job.groupStrategies,
+	}
+}
+
+func (job podCacheKey) cluster() clusterKey {
+	return clusterKey{kubeconfig: job.kubeconfig, context: job.context}
+}
+
+func (job podCacheKey) listOptions() metav1.ListOptions {
+	return metav1.ListOptions{
+		LabelSelector: job.labelSelector,
+		FieldSelector: job.fieldSelector,
+	}
+}
+
+func (k8Client Client) listNamespaces(cluster clusterKey) (*v1.NamespaceList, error) {
+	fmt.Printf("Getting namespace list for context: %v \n", cluster.context)
+	return k8Client.k8ClientSets[cluster].CoreV1().Namespaces().List(metav1.ListOptions{})
 }
 
+// buildConfigFromFlags uses the same loading rules as kubectl, so colon separated $KUBECONFIG files are merged and
+// ~/.kube/config is used as a default. kubeconfigPath takes precedence over both when it is not empty.
 func buildConfigFromFlags(context, kubeconfigPath string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfigPath
 	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath},
+		loadingRules,
 		&clientcmd.ConfigOverrides{
 			CurrentContext: context,
 		}).ClientConfig()
 }
+
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(os.Getenv("HOME"), path[2:])
+	}
+	return path
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: %v
+contexts:
+- name: dev
+  context:
+    cluster: cluster
+    user: user
+users:
+- name: user
+current-context: dev
+`
+
+func TestNewK8ClientSetsSameContextName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	servers := []string{"https://one:6443", "https://two:6443"}
+	clusters := make([]clusterKey, 0)
+	for index, server := range servers {
+		path := filepath.Join(dir, fmt.Sprintf("config %d", index))
+		if err := ioutil.WriteFile(path, []byte(fmt.Sprintf(testKubeconfig, server)), 0600); err != nil {
+			t.Fatal(err)
+		}
+		clusters = append(clusters, clusterKey{kubeconfig: path, context: "dev"})
+	}
+	clusters = append(clusters, clusters[0])
+
+	k8Client, err := NewK8ClientSets(clusters)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(k8Client.k8ClientSets) != 2 {
+		t.Fatalf("Invalid client count. Want: 2, Got: %v", len(k8Client.k8ClientSets))
+	}
+	for index, server := range servers {
+		if host := k8Client.k8Configs[clusters[index]].Host; host != server {
+			t.Errorf("Invalid host of %v. Want: %v, Got: %v", clusters[index].kubeconfig, server, host)
+		}
+	}
+}
+
+type fakeUpgrader struct {
+	conn *fakeConnection
+}
+
+func (u fakeUpgrader) NewConnection(resp *http.Response) (httpstream.Connection, error) {
+	return u.conn, nil
+}
+
+type fakeConnection struct {
+	httpstream.Connection
+	closeCh chan bool
+}
+
+func (c *fakeConnection) Close() error {
+	close(c.closeCh)
+	return nil
+}
+
+func (c *fakeConnection) CloseChan() <-chan bool {
+	return c.closeCh
+}
+
+func TestStoppableUpgrader(t *testing.T) {
+	stopCh := make(chan struct{})
+	conn := &fakeConnection{closeCh: make(chan bool)}
+	if _, err := (stoppableUpgrader{fakeUpgrader{conn}, stopCh}).NewConnection(nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	close(stopCh)
+	select {
+	case <-conn.closeCh:
+	case <-time.After(time.Second):
+		t.Errorf("Connection should be closed when stopped")
+	}
+}
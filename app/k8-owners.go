@@ -0,0 +1,102 @@
+package app
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sync"
+	"time"
+)
+
+// OwnerPollInterval is how often owners and controllers are listed without watch, they change much less often than pods.
+const OwnerPollInterval = 30 * time.Second
+
+// ownerLists are owners and controllers of pods in a namespace.
+type ownerLists struct {
+	replicaSets  []appsv1.ReplicaSet
+	jobs         []batchv1.Job
+	cronJobs     []batchv1beta1.CronJob
+	deployments  []appsv1.Deployment
+	statefulSets []appsv1.StatefulSet
+	daemonSets   []appsv1.DaemonSet
+	// errors are list errors, pods are still shown without the owners which couldn't be listed.
+	errors   []error
+	listTime time.Time
+}
+
+// ownerCache keeps owner lists between polls, so that every poll lists only pods.
+type ownerCache struct {
+	sync.Mutex
+	entries map[podCacheKey]ownerLists
+}
+
+func newOwnerCache() *ownerCache {
+	return &ownerCache{entries: make(map[podCacheKey]ownerLists)}
+}
+
+// get returns cached owners of the namespace, list is called when they are missing or older than OwnerPollInterval.
+func (oc *ownerCache) get(key podCacheKey, list func() ownerLists) ownerLists {
+	oc.Lock()
+	owners, ok := oc.entries[key]
+	oc.Unlock()
+	if ok && time.Since(owners.listTime) < OwnerPollInterval {
+		return owners
+	}
+
+	owners = list()
+	owners.listTime = time.Now()
+	oc.Lock()
+	oc.entries[key] = owners
+	oc.Unlock()
+	return owners
+}
+
+// listOwners lists owners of pods unfiltered, as the label selector is meant for pods, and controllers with the label
+// selector, which limits them to the selected applications.
+func listOwners(clientSet *kubernetes.Clientset, job getPodJob) ownerLists {
+	owners := ownerLists{}
+	if list, err := clientSet.AppsV1().ReplicaSets(job.namespace).List(metav1.ListOptions{}); err == nil {
+		owners.replicaSets = list.Items
+	} else {
+		owners.errors = append(owners.errors, err)
+	}
+	if list, err := clientSet.BatchV1().Jobs(job.namespace).List(metav1.ListOptions{}); err == nil {
+		owners.jobs = list.Items
+	} else {
+		owners.errors = append(owners.errors, err)
+	}
+	if list, err := clientSet.BatchV1beta1().CronJobs(job.namespace).List(metav1.ListOptions{}); err == nil {
+		owners.cronJobs = list.Items
+	} else {
+		owners.errors = append(owners.errors, err)
+	}
+	controllerOptions := metav1.ListOptions{LabelSelector: job.labelSelector}
+	if list, err := clientSet.AppsV1().Deployments(job.namespace).List(controllerOptions); err == nil {
+		owners.deployments = list.Items
+	} else {
+		owners.errors = append(owners.errors, err)
+	}
+	if list, err := clientSet.AppsV1().StatefulSets(job.namespace).List(controllerOptions); err == nil {
+		owners.statefulSets = list.Items
+	} else {
+		owners.errors = append(owners.errors, err)
+	}
+	if list, err := clientSet.AppsV1().DaemonSets(job.namespace).List(controllerOptions); err == nil {
+		owners.daemonSets = list.Items
+	} else {
+		owners.errors = append(owners.errors, err)
+	}
+	return owners
+}
+
+func (owners ownerLists) applyTo(result *PodListResult) {
+	result.replicaSets = owners.replicaSets
+	result.jobs = owners.jobs
+	result.cronJobs = owners.cronJobs
+	result.deployments = owners.deployments
+	result.statefulSets = owners.statefulSets
+	result.daemonSets = owners.daemonSets
+	result.ownerErrors = owners.errors
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOwnerCacheGet(t *testing.T) {
+	oc := newOwnerCache()
+	key := podCacheKey{context: "dev", namespace: "ns"}
+	listCount := 0
+	list := func() ownerLists {
+		listCount++
+		return ownerLists{errors: []error{errors.New("forbidden")}}
+	}
+
+	oc.get(key, list)
+	owners := oc.get(key, list)
+	if listCount != 1 || len(owners.errors) != 1 {
+		t.Errorf("Owners should be listed once within poll interval, Got: %v lists, %v errors", listCount, owners.errors)
+	}
+	oc.get(podCacheKey{context: "dev", namespace: "other"}, list)
+	if listCount != 2 {
+		t.Errorf("Owners of every namespace should be listed, Got: %v lists", listCount)
+	}
+
+	owners.listTime = time.Now().Add(-OwnerPollInterval)
+	oc.entries[key] = owners
+	oc.get(key, list)
+	if listCount != 3 {
+		t.Errorf("Owners should be listed again after poll interval, Got: %v lists", listCount)
+	}
+}
+
+func TestOwnerErrorsMessage(t *testing.T) {
+	plr := PodListResult{namespace: "ns", ownerErrors: []error{errors.New("replicasets.apps is forbidden"), errors.New("jobs.batch is forbidden")}}
+	ns := toNamespace(&plr)
+	expected := "No resources found. Owners not listed, grouping may be incomplete: replicasets.apps is forbidden (and 1 more)"
+	if ns.nsMessage.message != expected {
+		t.Errorf("Invalid message.\nWant: %v\nGot:  %v", expected, ns.nsMessage.message)
+	}
+}
@@ -0,0 +1,511 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/gdamore/tcell"
+	"io/ioutil"
+	v1 "k8s.io/api/core/v1"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// logBufferSize is the maximum number of lines kept in the log frame, older lines are dropped.
+	logBufferSize  = 10000
+	defaultLogTail = 500
+	logRedrawDelay = 50 * time.Millisecond
+	// logMaxLineSize is the longest log line, e.g. a JSON log with a stack trace, a longer one ends the stream.
+	logMaxLineSize = 1024 * 1024
+)
+
+// logPrefixColours are used in turn for prefixes of different pods when logs of several pods are merged.
+var logPrefixColours = []tcell.Color{
+	tcell.ColorTeal, tcell.ColorYellow, tcell.ColorFuchsia, tcell.ColorGreen,
+	tcell.ColorAqua, tcell.ColorOlive, tcell.ColorPurple, tcell.ColorBlue,
+}
+
+// logSource is a single container whose logs are shown.
+type logSource struct {
+	namespace *Namespace
+	pod       string
+	container string
+	colour    tcell.Color
+}
+
+func (ls logSource) prefix() string {
+	return fmt.Sprintf("[%v/%v] ", ls.pod, ls.container)
+}
+
+type logLine struct {
+	source *logSource
+	text   string
+}
+
+// logOptions are the settings which require streams to be restarted when they change.
+type logOptions struct {
+	previous bool
+	// tailLines and sinceSeconds are not limiting logs when they are 0.
+	tailLines    int64
+	sinceSeconds int64
+}
+
+func (lo logOptions) podLogOptions(container string) *v1.PodLogOptions {
+	options := &v1.PodLogOptions{Container: container, Follow: !lo.previous, Previous: lo.previous}
+	if lo.tailLines > 0 {
+		tailLines := lo.tailLines
+		options.TailLines = &tailLines
+	}
+	if lo.sinceSeconds > 0 {
+		sinceSeconds := lo.sinceSeconds
+		options.SinceSeconds = &sinceSeconds
+	}
+	return options
+}
+
+// LogFrame is a full screen log viewer which streams logs of one or more containers and merges them by arrival.
+type LogFrame struct {
+	sync.Mutex
+
+	width, height int
+	visible       bool
+	k8Client      K8Client
+	sources       []logSource
+	options       logOptions
+	lines         []logLine
+	// offset is the index of the first line shown, it is kept at the end of the buffer while following.
+	offset int
+	follow bool
+	query  string
+	// match is the index of the line found by the last search, -1 when there is none.
+	match int
+	// message is shown in the title until streams are restarted, e.g. stream errors.
+	message string
+	// generation is increased by every restart, lines of streams from previous generations are dropped.
+	generation int
+	stopCh     chan struct{}
+	redrawCh   chan struct{}
+}
+
+func NewLogFrame(s tcell.Screen, k8Client K8Client, ns *Namespace, podNames []string, container string) *LogFrame {
+	width, height := s.Size()
+	lf := &LogFrame{
+		width:    width,
+		height:   height,
+		k8Client: k8Client,
+		options:  logOptions{tailLines: defaultLogTail},
+		follow:   true,
+		match:    -1,
+		redrawCh: make(chan struct{}, 1),
+	}
+	for index, pod := range podNames {
+		lf.sources = append(lf.sources, logSource{
+			namespace: ns,
+			pod:       pod,
+			container: container,
+			colour:    logPrefixColours[index%len(logPrefixColours)],
+		})
+	}
+	return lf
+}
+
+// open shows the frame and starts streaming, logs are drawn as they arrive until close is called.
+func (lf *LogFrame) open(s tcell.Screen) {
+	lf.visible = true
+	go func() {
+		for range lf.redrawCh {
+			lf.draw(s)
+			s.Show()
+			time.Sleep(logRedrawDelay)
+		}
+	}()
+	lf.restart()
+}
+
+func (lf *LogFrame) close() {
+	lf.Lock()
+	defer lf.Unlock()
+	lf.visible = false
+	lf.stop()
+	close(lf.redrawCh)
+}
+
+// restart clears the buffer and starts streams with current options.
+func (lf *LogFrame) restart() {
+	lf.Lock()
+	lf.stop()
+	lf.lines = nil
+	lf.offset = 0
+	lf.match = -1
+	lf.message = ""
+	lf.generation++
+	lf.stopCh = make(chan struct{})
+	stopCh, generation, options := lf.stopCh, lf.generation, lf.options
+	lf.Unlock()
+
+	for index := range lf.sources {
+		go lf.stream(&lf.sources[index], options, generation, stopCh)
+	}
+	lf.requestRedraw()
+}
+
+func (lf *LogFrame) stop() {
+	if lf.stopCh != nil {
+		close(lf.stopCh)
+		lf.stopCh = nil
+	}
+}
+
+func (lf *LogFrame) stream(source *logSource, options logOptions, generation int, stopCh chan struct{}) {
+	ns := source.namespace
+	stream, err := lf.k8Client.streamLogs(ns.cluster(), ns.name, source.pod, options.podLogOptions(source.container))
+	if err != nil {
+		lf.setMessage(fmt.Sprintf("%v: %v", source.pod, err), generation)
+		return
+	}
+	go func() {
+		<-stopCh
+		_ = stream.Close()
+	}()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), logMaxLineSize)
+	for scanner.Scan() {
+		select {
+		case <-stopCh:
+			return
+		default:
+			lf.append(logLine{source: source, text: scanner.Text()}, generation)
+		}
+	}
+	select {
+	case <-stopCh:
+		// Stream was closed by a restart or by closing the frame.
+		return
+	default:
+	}
+	if err := scanner.Err(); err != nil {
+		lf.setMessage(fmt.Sprintf("%v/%v: %v", source.pod, source.container, err), generation)
+	} else if !options.previous {
+		// Followed logs end when the container stops, logs of the previous instance just have no more lines.
+		lf.setMessage(fmt.Sprintf("%v/%v: log stream ended", source.pod, source.container), generation)
+	}
+}
+
+func (lf *LogFrame) append(line logLine, generation int) {
+	lf.Lock()
+	if generation != lf.generation {
+		lf.Unlock()
+		return
+	}
+	lf.lines = append(lf.lines, line)
+	if len(lf.lines) > logBufferSize {
+		dropped := len(lf.lines) - logBufferSize
+		lf.lines = lf.lines[dropped:]
+		lf.offset -= dropped
+		if lf.offset < 0 {
+			lf.offset = 0
+		}
+		if lf.match >= 0 {
+			lf.match -= dropped
+		}
+	}
+	if lf.follow {
+		lf.offset = lf.maxOffset()
+	}
+	lf.Unlock()
+	lf.requestRedraw()
+}
+
+func (lf *LogFrame) setMessage(message string, generation int) {
+	lf.Lock()
+	if generation != lf.generation {
+		lf.Unlock()
+		return
+	}
+	lf.message = message
+	lf.Unlock()
+	lf.requestRedraw()
+}
+
+func (lf *LogFrame) requestRedraw() {
+	lf.Lock()
+	defer lf.Unlock()
+	if !lf.visible {
+		return
+	}
+	select {
+	case lf.redrawCh <- struct{}{}:
+	default:
+	}
+}
+
+// linesHeight is the number of rows available for log lines, first row is the title and last two are help and status bar.
+func (lf *LogFrame) linesHeight() int {
+	return lf.height - 3
+}
+
+func (lf *LogFrame) maxOffset() int {
+	offset := len(lf.lines) - lf.linesHeight()
+	if offset < 0 {
+		return 0
+	}
+	return offset
+}
+
+// scroll moves shown lines by n, scrolling up pauses following and reaching the end resumes it.
+func (lf *LogFrame) scroll(n int) {
+	lf.Lock()
+	lf.offset += n
+	if lf.offset < 0 {
+		lf.offset = 0
+	}
+	if lf.offset >= lf.maxOffset() {
+		lf.offset = lf.maxOffset()
+		lf.follow = true
+	} else {
+		lf.follow = false
+	}
+	lf.Unlock()
+	lf.requestRedraw()
+}
+
+func (lf *LogFrame) toggleFollow() {
+	lf.Lock()
+	lf.follow = !lf.follow
+	if lf.follow {
+		lf.offset = lf.maxOffset()
+	}
+	lf.Unlock()
+	lf.requestRedraw()
+}
+
+func (lf *LogFrame) currentOptions() logOptions {
+	lf.Lock()
+	defer lf.Unlock()
+	return lf.options
+}
+
+func (lf *LogFrame) setOptions(options logOptions) {
+	lf.Lock()
+	lf.options = options
+	lf.follow = true
+	lf.Unlock()
+	lf.restart()
+}
+
+func (lf *LogFrame) setQuery(query string) {
+	lf.Lock()
+	lf.query = query
+	lf.match = -1
+	lf.Unlock()
+	lf.requestRedraw()
+}
+
+// searchNext scrolls to the next line containing the query, starting after the previous match or the first shown line.
+// It returns false when no line matches.
+func (lf *LogFrame) searchNext(forward bool) bool {
+	lf.Lock()
+	defer lf.Unlock()
+	start := lf.offset
+	if lf.match >= 0 {
+		start = lf.match
+	}
+	index := findLine(lf.lines, lf.query, start, forward)
+	if index < 0 {
+		return false
+	}
+	lf.follow = false
+	lf.match = index
+	lf.offset = index
+	if lf.offset > lf.maxOffset() {
+		lf.offset = lf.maxOffset()
+	}
+	select {
+	case lf.redrawCh <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// findLine returns index of the first line after start containing query, wrapping around, or -1 when none does.
+func findLine(lines []logLine, query string, start int, forward bool) int {
+	if query == "" || len(lines) == 0 {
+		return -1
+	}
+	step := 1
+	if !forward {
+		step = -1
+	}
+	for count := 1; count <= len(lines); count++ {
+		index := ((start+count*step)%len(lines) + len(lines)) % len(lines)
+		if len(matchIndexes(lines[index].text, query)) > 0 {
+			return index
+		}
+	}
+	return -1
+}
+
+// matchIndexes returns byte offsets of case insensitive, non overlapping occurrences of query in text.
+func matchIndexes(text, query string) []int {
+	indexes := make([]int, 0)
+	if query == "" {
+		return indexes
+	}
+	lowerText, lowerQuery := strings.ToLower(text), strings.ToLower(query)
+	if len(lowerText) != len(text) || len(lowerQuery) != len(query) {
+		// Offsets would not match the original text, so fall back to case sensitive search.
+		lowerText, lowerQuery = text, query
+	}
+	for start := 0; start <= len(lowerText)-len(lowerQuery); {
+		index := strings.Index(lowerText[start:], lowerQuery)
+		if index < 0 {
+			break
+		}
+		indexes = append(indexes, start+index)
+		start += index + len(lowerQuery)
+	}
+	return indexes
+}
+
+// save writes the whole buffer to a file, with pod prefixes when logs of several pods are shown.
+func (lf *LogFrame) save(path string) error {
+	lf.Lock()
+	content := lf.content()
+	lf.Unlock()
+	return ioutil.WriteFile(expandHome(path), []byte(content), 0644)
+}
+
+func (lf *LogFrame) content() string {
+	var builder strings.Builder
+	for _, line := range lf.lines {
+		if len(lf.sources) > 1 {
+			builder.WriteString(line.source.prefix())
+		}
+		builder.WriteString(line.text)
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+func (lf *LogFrame) defaultFileName() string {
+	return fmt.Sprintf("%v-%v.log", lf.sources[0].pod, time.Now().Format("20060102-150405"))
+}
+
+func (lf *LogFrame) title() string {
+	source := lf.sources[0]
+	parts := []string{fmt.Sprintf("Logs: %v/%v -c %v", source.namespace.name, source.pod, source.container)}
+	if len(lf.sources) > 1 {
+		parts[0] = fmt.Sprintf("Logs: %v, %d pods -c %v", source.namespace.name, len(lf.sources), source.container)
+	}
+	if lf.follow {
+		parts = append(parts, "following")
+	} else {
+		parts = append(parts, "paused")
+	}
+	if lf.options.previous {
+		parts = append(parts, "previous")
+	}
+	if lf.options.tailLines > 0 {
+		parts = append(parts, fmt.Sprintf("tail %d", lf.options.tailLines))
+	}
+	if lf.options.sinceSeconds > 0 {
+		parts = append(parts, fmt.Sprintf("since %v", time.Duration(lf.options.sinceSeconds)*time.Second))
+	}
+	if lf.query != "" {
+		parts = append(parts, "search: "+lf.query)
+	}
+	parts = append(parts, fmt.Sprintf("%d lines", len(lf.lines)))
+	if lf.message != "" {
+		parts = append(parts, lf.message)
+	}
+	return strings.Join(parts, " | ")
+}
+
+func (lf *LogFrame) draw(s tcell.Screen) {
+	lf.Lock()
+	defer lf.Unlock()
+	if !lf.visible {
+		return
+	}
+	s.HideCursor()
+	drawS(s, lf.title(), 0, 0, lf.width, tcell.StyleDefault.Reverse(true))
+	for row := 0; row < lf.linesHeight(); row++ {
+		index := lf.offset + row
+		if index >= len(lf.lines) {
+			drawS(s, "", 0, row+1, lf.width, tcell.StyleDefault)
+			continue
+		}
+		lf.drawLine(s, lf.lines[index], row+1)
+	}
+	help := "Esc = close   p = pause/follow   P = previous   t = tail   s = since   / = search   n/N = next/previous   w = save"
+	drawS(s, help, 0, lf.height-2, lf.width, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+}
+
+func (lf *LogFrame) drawLine(s tcell.Screen, line logLine, y int) {
+	x := 0
+	if len(lf.sources) > 1 {
+		x = drawRunes(s, line.source.prefix(), x, y, lf.width, tcell.StyleDefault.Foreground(line.source.colour))
+	}
+	matchStyle := tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack)
+	position := 0
+	for _, index := range matchIndexes(line.text, lf.query) {
+		x = drawRunes(s, line.text[position:index], x, y, lf.width, tcell.StyleDefault)
+		position = index + len(lf.query)
+		x = drawRunes(s, line.text[index:position], x, y, lf.width, matchStyle)
+	}
+	x = drawRunes(s, line.text[position:], x, y, lf.width, tcell.StyleDefault)
+	drawS(s, "", x, y, lf.width-x, tcell.StyleDefault)
+}
+
+// drawRunes draws value starting at x up to maxX and returns x after the last drawn rune. Unlike drawS it handles
+// multi byte characters, which are common in logs.
+func drawRunes(s tcell.Screen, value string, x, y, maxX int, style tcell.Style) int {
+	for _, r := range value {
+		if x >= maxX {
+			break
+		}
+		if r == '\t' {
+			r = ' '
+		}
+		s.SetContent(x, y, r, nil, style)
+		x++
+	}
+	return x
+}
+
+func (lf *LogFrame) resize(s tcell.Screen) {
+	lf.Lock()
+	lf.width, lf.height = s.Size()
+	if lf.follow || lf.offset > lf.maxOffset() {
+		lf.offset = lf.maxOffset()
+	}
+	lf.Unlock()
+	lf.requestRedraw()
+}
+
+// parseTail parses number of tail lines, empty value means all lines.
+func parseTail(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	tail, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || tail < 0 {
+		return 0, fmt.Errorf("invalid number of lines: '%v'", value)
+	}
+	return tail, nil
+}
+
+// parseSince parses a duration like 10m or 1h, empty value means no limit.
+func parseSince(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	since, err := time.ParseDuration(value)
+	if err != nil || since < time.Second {
+		return 0, fmt.Errorf("invalid duration: '%v'", value)
+	}
+	return int64(since / time.Second), nil
+}
@@ -0,0 +1,171 @@
+package app
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"io"
+	"io/ioutil"
+	v1 "k8s.io/api/core/v1"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeLogClient struct {
+	K8Client
+	logs map[string]string
+}
+
+func (c fakeLogClient) streamLogs(cluster clusterKey, namespace, pod string, options *v1.PodLogOptions) (io.ReadCloser, error) {
+	if logs, ok := c.logs[pod]; ok {
+		return ioutil.NopCloser(strings.NewReader(logs)), nil
+	}
+	return nil, fmt.Errorf("pod %v not found", pod)
+}
+
+func TestLogFrameMergesPods(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	_ = screen.Init()
+	client := fakeLogClient{logs: map[string]string{"web-1": "a\nb\n", "web-2": "c\n"}}
+	lf := NewLogFrame(screen, client, &Namespace{name: "ns", context: "dev"}, []string{"web-1", "web-2"}, "app")
+	lf.open(screen)
+	defer lf.close()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		lf.Lock()
+		count := len(lf.lines)
+		lf.Unlock()
+		if count == 3 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	lf.Lock()
+	lines := strings.Split(strings.TrimSpace(lf.content()), "\n")
+	lf.Unlock()
+	sort.Strings(lines)
+	expected := []string{"[web-1/app] a", "[web-1/app] b", "[web-2/app] c"}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Invalid merged logs.\nWant: %q\nGot:  %q", expected, lines)
+	}
+	if lf.sources[0].colour == lf.sources[1].colour {
+		t.Errorf("Pods should have different prefix colours")
+	}
+}
+
+func TestMatchIndexes(t *testing.T) {
+	testTable := []struct {
+		text     string
+		query    string
+		expected []int
+	}{
+		{"ERROR: connection error", "error", []int{0, 18}},
+		{"aaaa", "aa", []int{0, 2}},
+		{"nothing here", "error", []int{}},
+		{"anything", "", []int{}},
+	}
+	for index, tc := range testTable {
+		t.Run(fmt.Sprintf("%v %v", index, tc.query), func(t *testing.T) {
+			if indexes := matchIndexes(tc.text, tc.query); !reflect.DeepEqual(indexes, tc.expected) {
+				t.Errorf("Invalid indexes. Want: %v, Got: %v", tc.expected, indexes)
+			}
+		})
+	}
+}
+
+func TestFindLine(t *testing.T) {
+	lines := []logLine{{text: "error 1"}, {text: "ok"}, {text: "ok"}, {text: "Error 2"}}
+	if index := findLine(lines, "error", 0, true); index != 3 {
+		t.Errorf("Invalid next line. Want: 3, Got: %v", index)
+	}
+	if index := findLine(lines, "error", 3, true); index != 0 {
+		t.Errorf("Search should wrap around. Want: 0, Got: %v", index)
+	}
+	if index := findLine(lines, "error", 0, false); index != 3 {
+		t.Errorf("Invalid previous line. Want: 3, Got: %v", index)
+	}
+	if index := findLine(lines, "missing", 0, true); index != -1 {
+		t.Errorf("Invalid line for missing query. Want: -1, Got: %v", index)
+	}
+}
+
+func TestParseLogLimits(t *testing.T) {
+	if tail, err := parseTail("100"); err != nil || tail != 100 {
+		t.Errorf("Invalid tail. Want: 100, Got: %v, %v", tail, err)
+	}
+	if tail, err := parseTail(""); err != nil || tail != 0 {
+		t.Errorf("Empty tail should mean all lines, Got: %v, %v", tail, err)
+	}
+	if _, err := parseTail("-1"); err == nil {
+		t.Errorf("Expected error for negative tail")
+	}
+	if since, err := parseSince("10m"); err != nil || since != 600 {
+		t.Errorf("Invalid since. Want: 600, Got: %v, %v", since, err)
+	}
+	if _, err := parseSince("10"); err == nil {
+		t.Errorf("Expected error for duration without unit")
+	}
+}
+
+func TestLogFrameDropsSupersededLines(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	_ = screen.Init()
+	lf := NewLogFrame(screen, fakeLogClient{logs: map[string]string{"web-1": ""}}, &Namespace{name: "ns", context: "dev"}, []string{"web-1"}, "app")
+	lf.open(screen)
+	defer lf.close()
+
+	lf.Lock()
+	previous := lf.generation
+	lf.Unlock()
+	lf.setOptions(logOptions{previous: true})
+	lf.append(logLine{source: &lf.sources[0], text: "old"}, previous)
+	lf.setMessage("old error", previous)
+
+	lf.Lock()
+	defer lf.Unlock()
+	if len(lf.lines) != 0 || lf.message != "" {
+		t.Errorf("Lines of a superseded stream should be dropped, Got: %v lines, message %q", len(lf.lines), lf.message)
+	}
+}
+
+func TestLogFrameLongLines(t *testing.T) {
+	testTable := []struct {
+		name            string
+		logs            string
+		expectedLines   int
+		expectedMessage string
+	}{
+		{"long", strings.Repeat("a", 100*1024) + "\nb\n", 2, "web-1/app: log stream ended"},
+		{"too long", "a\n" + strings.Repeat("a", logMaxLineSize+1) + "\n", 1, "web-1/app: bufio.Scanner: token too long"},
+	}
+	for index, tc := range testTable {
+		t.Run(fmt.Sprintf("%v %v", index, tc.name), func(t *testing.T) {
+			screen := tcell.NewSimulationScreen("")
+			_ = screen.Init()
+			client := fakeLogClient{logs: map[string]string{"web-1": tc.logs}}
+			lf := NewLogFrame(screen, client, &Namespace{name: "ns", context: "dev"}, []string{"web-1"}, "app")
+			lf.open(screen)
+			defer lf.close()
+
+			deadline := time.Now().Add(time.Second)
+			for {
+				lf.Lock()
+				message := lf.message
+				lf.Unlock()
+				if message != "" || time.Now().After(deadline) {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+			lf.Lock()
+			defer lf.Unlock()
+			if len(lf.lines) != tc.expectedLines || lf.message != tc.expectedMessage {
+				t.Errorf("Invalid result. Want: %v lines, %q, Got: %v lines, %q", tc.expectedLines, tc.expectedMessage, len(lf.lines), lf.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	mutationDelete = "delete"
+	mutationScale  = "scale"
+)
+
+// mutation is a change of a resource made through the API, shortcuts with a mutation are confirmed before it is made.
+type mutation struct {
+	verb      string
+	namespace *Namespace
+	kind      string
+	name      string
+	// replicas is the current desired replica count, it is offered as a default when scaling.
+	replicas int32
+}
+
+func (m mutation) resource() string {
+	return strings.ToLower(m.kind) + "/" + m.name
+}
+
+func (m mutation) String() string {
+	return m.verb + " " + m.resource()
+}
+
+// target names the context and the namespace of the resource, so that it is clear where the change is made.
+func (m mutation) target() string {
+	return fmt.Sprintf("%v in %v/%v", m.resource(), m.namespace.context, m.namespace.name)
+}
+
+// parseReplicas parses replica count typed in by the user.
+func parseReplicas(value string) (int32, error) {
+	replicas, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+	if err != nil || replicas < 0 {
+		return 0, fmt.Errorf("invalid replica count '%v', expected a number from 0", value)
+	}
+	return int32(replicas), nil
+}
+
+// podGroupReplicas returns desired replica count of the pod group, or its pod count when the controller is unknown.
+func podGroupReplicas(pg *PodGroup) int32 {
+	if pg.replicas != nil {
+		return pg.replicas.desired
+	}
+	return int32(len(pg.pods))
+}
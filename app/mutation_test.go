@@ -0,0 +1,46 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseReplicas(t *testing.T) {
+	testTable := []struct {
+		value    string
+		expected int32
+		isError  bool
+	}{
+		{"3", 3, false},
+		{" 0 ", 0, false},
+		{"", 0, true},
+		{"-1", 0, true},
+		{"2.5", 0, true},
+		{"3000000000", 0, true},
+	}
+	for index, tc := range testTable {
+		t.Run(fmt.Sprintf("%v %q", index, tc.value), func(t *testing.T) {
+			replicas, err := parseReplicas(tc.value)
+			if (err != nil) != tc.isError || replicas != tc.expected {
+				t.Errorf("Want: %v (error %v), Got: %v (%v)", tc.expected, tc.isError, replicas, err)
+			}
+		})
+	}
+}
+
+func TestPodMutations(t *testing.T) {
+	ns := &Namespace{name: "ns", context: "dev"}
+	pg := &PodGroup{name: "web", kind: KindDeployment, namespace: ns, replicas: &replicaStatus{desired: 3}}
+	pod := &Pod{name: "web-1", podGroup: pg}
+
+	mutations := make([]string, 0)
+	for _, sc := range shortcuts(pod) {
+		if sc.mutation != nil {
+			mutations = append(mutations, fmt.Sprintf("%v %v %d", sc.label, sc.mutation.target(), sc.mutation.replicas))
+		}
+	}
+	expected := fmt.Sprint([]string{"delete pod pod/web-1 in dev/ns 0", "scale deployment/web in dev/ns 3"})
+	if fmt.Sprint(mutations) != expected {
+		t.Errorf("Invalid mutations.\nWant: %v\nGot:  %v", expected, mutations)
+	}
+}
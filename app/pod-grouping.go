@@ -0,0 +1,181 @@
+package app
+
+import (
+	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"regexp"
+	"strings"
+)
+
+const (
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+	KindDaemonSet   = "DaemonSet"
+	KindReplicaSet  = "ReplicaSet"
+	KindJob         = "Job"
+	KindCronJob     = "CronJob"
+	KindPod         = "Pod"
+
+	UngroupedPodGroupName = "_"
+
+	StrategyOwner      = "owner"
+	StrategyLabel      = "label"
+	StrategyAnnotation = "annotation"
+	StrategyRegex      = "regex"
+)
+
+// DefaultPodGroupLabels are label keys which are checked in order for pods without a controller.
+var DefaultPodGroupLabels = []string{"deployment", "statefulSet", "job-name"}
+
+// labelKinds maps well known grouping labels to the kind of resource they are named after.
+var labelKinds = map[string]string{
+	"deployment":  KindDeployment,
+	"statefulSet": KindStatefulSet,
+	"job-name":    KindJob,
+}
+
+// groupStrategy is a single way of finding pod group name, parsed from '<type>[:<key or pattern>]' string.
+type groupStrategy struct {
+	strategyType string
+	key          string
+	regex        *regexp.Regexp
+}
+
+func parseGroupStrategies(specs []string) ([]groupStrategy, error) {
+	strategies := make([]groupStrategy, 0, len(specs))
+	for _, spec := range specs {
+		strategy, err := parseGroupStrategy(spec)
+		if err != nil {
+			return nil, err
+		}
+		strategies = append(strategies, strategy)
+	}
+	return strategies, nil
+}
+
+func parseGroupStrategy(spec string) (groupStrategy, error) {
+	strategyType, key := spec, ""
+	if index := strings.Index(spec, ":"); index >= 0 {
+		strategyType, key = spec[:index], spec[index+1:]
+	}
+
+	strategy := groupStrategy{strategyType: strategyType, key: key}
+	switch strategyType {
+	case StrategyOwner:
+		if key != "" {
+			return groupStrategy{}, fmt.Errorf("'%v' strategy does not take a value: '%v'", StrategyOwner, spec)
+		}
+	case StrategyLabel, StrategyAnnotation:
+		if key == "" {
+			return groupStrategy{}, fmt.Errorf("'%v' strategy requires a key: '%v'", strategyType, spec)
+		}
+	case StrategyRegex:
+		regex, err := regexp.Compile(key)
+		if err != nil {
+			return groupStrategy{}, fmt.Errorf("invalid regex in '%v': %v", spec, err)
+		}
+		strategy.regex = regex
+	default:
+		return groupStrategy{}, fmt.Errorf("unknown grouping strategy '%v'", spec)
+	}
+	return strategy, nil
+}
+
+// defaultGroupBy groups by owner first and then by labels in the given order.
+func defaultGroupBy(podGroupLabels []string) []string {
+	groupBy := []string{StrategyOwner}
+	for _, label := range podGroupLabels {
+		groupBy = append(groupBy, StrategyLabel+":"+label)
+	}
+	return groupBy
+}
+
+func hasStrategy(strategies []groupStrategy, strategyType string) bool {
+	for _, strategy := range strategies {
+		if strategy.strategyType == strategyType {
+			return true
+		}
+	}
+	return false
+}
+
+type podGrouper struct {
+	strategies  []groupStrategy
+	replicaSets map[string]*appsv1.ReplicaSet
+	jobs        map[string]*batchv1.Job
+}
+
+func newPodGrouper(plr *PodListResult) podGrouper {
+	grouper := podGrouper{
+		strategies:  plr.groupStrategies,
+		replicaSets: make(map[string]*appsv1.ReplicaSet),
+		jobs:        make(map[string]*batchv1.Job),
+	}
+	for index := range plr.replicaSets {
+		grouper.replicaSets[plr.replicaSets[index].Name] = &plr.replicaSets[index]
+	}
+	for index := range plr.jobs {
+		grouper.jobs[plr.jobs[index].Name] = &plr.jobs[index]
+	}
+	return grouper
+}
+
+// groupOf returns pod group name and kind of the resource the group is named after, using the first strategy which
+// matches the pod. Kind is empty when it is unknown.
+func (g podGrouper) groupOf(pod *v1.Pod) (name string, kind string) {
+	for _, strategy := range g.strategies {
+		switch strategy.strategyType {
+		case StrategyOwner:
+			// Controller references are followed up to the top level controller.
+			if ref := metav1.GetControllerOf(pod); ref != nil {
+				return g.topLevelOwner(pod, ref)
+			}
+		case StrategyLabel:
+			if value := pod.Labels[strategy.key]; value != "" {
+				return value, labelKinds[strategy.key]
+			}
+		case StrategyAnnotation:
+			if value := pod.Annotations[strategy.key]; value != "" {
+				return value, ""
+			}
+		case StrategyRegex:
+			// Group name is the first capture group, or the whole match when pattern has no groups.
+			if match := strategy.regex.FindStringSubmatch(pod.Name); match != nil {
+				if len(match) > 1 && match[1] != "" {
+					return match[1], ""
+				}
+				if match[0] != "" {
+					return match[0], ""
+				}
+			}
+		}
+	}
+	return UngroupedPodGroupName, ""
+}
+
+func (g podGrouper) topLevelOwner(pod *v1.Pod, ref *metav1.OwnerReference) (name string, kind string) {
+	switch ref.Kind {
+	case KindReplicaSet:
+		if rs, ok := g.replicaSets[ref.Name]; ok {
+			if rsRef := metav1.GetControllerOf(rs); rsRef != nil {
+				return rsRef.Name, rsRef.Kind
+			}
+			return rs.Name, KindReplicaSet
+		}
+		// When ReplicaSets can't be listed, rely on Deployment's ReplicaSets being named '<deployment>-<pod-template-hash>'.
+		hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+		if hash != "" && strings.HasSuffix(ref.Name, "-"+hash) {
+			return strings.TrimSuffix(ref.Name, "-"+hash), KindDeployment
+		}
+	case KindJob:
+		if job, ok := g.jobs[ref.Name]; ok {
+			if jobRef := metav1.GetControllerOf(job); jobRef != nil {
+				return jobRef.Name, jobRef.Kind
+			}
+		}
+	}
+	return ref.Name, ref.Kind
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestGroupOf(t *testing.T) {
+	strategies, err := parseGroupStrategies(defaultGroupBy(DefaultPodGroupLabels))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plr := PodListResult{
+		groupStrategies: strategies,
+		replicaSets: []appsv1.ReplicaSet{
+			{ObjectMeta: fakeMeta("web-5d8f9", fakeOwner(KindDeployment, "web"))},
+			{ObjectMeta: fakeMeta("standalone")},
+		},
+		jobs: []batchv1.Job{
+			{ObjectMeta: fakeMeta("backup-1573", fakeOwner(KindCronJob, "backup"))},
+			{ObjectMeta: fakeMeta("migration")},
+		},
+	}
+
+	testTable := []struct {
+		name         string
+		pod          v1.Pod
+		expectedName string
+		expectedKind string
+	}{
+		{
+			name:         "deployment_through_replica_set",
+			pod:          v1.Pod{ObjectMeta: fakeMeta("web-5d8f9-abcde", fakeOwner(KindReplicaSet, "web-5d8f9"))},
+			expectedName: "web",
+			expectedKind: KindDeployment,
+		},
+		{
+			name:         "replica_set_without_deployment",
+			pod:          v1.Pod{ObjectMeta: fakeMeta("standalone-abcde", fakeOwner(KindReplicaSet, "standalone"))},
+			expectedName: "standalone",
+			expectedKind: KindReplicaSet,
+		},
+		{
+			name: "unknown_replica_set_with_pod_template_hash",
+			pod: v1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "api-7c9d-abcde",
+				Labels:          map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "7c9d"},
+				OwnerReferences: []metav1.OwnerReference{fakeOwner(KindReplicaSet, "api-7c9d")},
+			}},
+			expectedName: "api",
+			expectedKind: KindDeployment,
+		},
+		{
+			name:         "cron_job_through_job",
+			pod:          v1.Pod{ObjectMeta: fakeMeta("backup-1573-abcde", fakeOwner(KindJob, "backup-1573"))},
+			expectedName: "backup",
+			expectedKind: KindCronJob,
+		},
+		{
+			name:         "job_without_cron_job",
+			pod:          v1.Pod{ObjectMeta: fakeMeta("migration-abcde", fakeOwner(KindJob, "migration"))},
+			expectedName: "migration",
+			expectedKind: KindJob,
+		},
+		{
+			name:         "stateful_set",
+			pod:          v1.Pod{ObjectMeta: fakeMeta("db-0", fakeOwner(KindStatefulSet, "db"))},
+			expectedName: "db",
+			expectedKind: KindStatefulSet,
+		},
+		{
+			name:         "daemon_set",
+			pod:          v1.Pod{ObjectMeta: fakeMeta("agent-abcde", fakeOwner(KindDaemonSet, "agent"))},
+			expectedName: "agent",
+			expectedKind: KindDaemonSet,
+		},
+		{
+			name:         "label_fallback",
+			pod:          v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "legacy", Labels: map[string]string{"statefulSet": "legacy-set"}}},
+			expectedName: "legacy-set",
+			expectedKind: KindStatefulSet,
+		},
+		{
+			name:         "ungrouped",
+			pod:          v1.Pod{ObjectMeta: fakeMeta("lonely")},
+			expectedName: UngroupedPodGroupName,
+			expectedKind: "",
+		},
+	}
+
+	grouper := newPodGrouper(&plr)
+	for index := range testTable {
+		tc := &testTable[index]
+		t.Run(fmt.Sprintf("%v %v", index, tc.name), func(t *testing.T) {
+			name, kind := grouper.groupOf(&tc.pod)
+			if name != tc.expectedName {
+				t.Errorf("Invalid group name. Want: %v, Got: %v", tc.expectedName, name)
+			}
+			if kind != tc.expectedKind {
+				t.Errorf("Invalid group kind. Want: %v, Got: %v", tc.expectedKind, kind)
+			}
+		})
+	}
+}
+
+func TestGroupOfWithStrategies(t *testing.T) {
+	testTable := []struct {
+		name         string
+		groupBy      []string
+		pod          v1.Pod
+		expectedName string
+		expectedKind string
+	}{
+		{
+			name:    "label_before_owner",
+			groupBy: []string{"label:app.kubernetes.io/name", "owner"},
+			pod: v1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "db-0",
+				Labels:          map[string]string{"app.kubernetes.io/name": "postgres"},
+				OwnerReferences: []metav1.OwnerReference{fakeOwner(KindStatefulSet, "db")},
+			}},
+			expectedName: "postgres",
+			expectedKind: "",
+		},
+		{
+			name:         "owner_when_label_is_missing",
+			groupBy:      []string{"label:app.kubernetes.io/name", "owner"},
+			pod:          v1.Pod{ObjectMeta: fakeMeta("db-0", fakeOwner(KindStatefulSet, "db"))},
+			expectedName: "db",
+			expectedKind: KindStatefulSet,
+		},
+		{
+			name:    "annotation",
+			groupBy: []string{"annotation:team"},
+			pod: v1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:        "worker",
+				Annotations: map[string]string{"team": "payments"},
+			}},
+			expectedName: "payments",
+			expectedKind: "",
+		},
+		{
+			name:         "regex_capture_group",
+			groupBy:      []string{`regex:^(.+)-[a-z0-9]+-[a-z0-9]{5}$`},
+			pod:          v1.Pod{ObjectMeta: fakeMeta("checkout-api-7c9d5-x2x4z")},
+			expectedName: "checkout-api",
+			expectedKind: "",
+		},
+		{
+			name:         "regex_whole_match",
+			groupBy:      []string{`regex:^[a-z]+`},
+			pod:          v1.Pod{ObjectMeta: fakeMeta("checkout-api-7c9d5-x2x4z")},
+			expectedName: "checkout",
+			expectedKind: "",
+		},
+		{
+			name:         "no_strategy_matches",
+			groupBy:      []string{"label:app", `regex:^foo`},
+			pod:          v1.Pod{ObjectMeta: fakeMeta("bar")},
+			expectedName: UngroupedPodGroupName,
+			expectedKind: "",
+		},
+	}
+
+	for index := range testTable {
+		tc := &testTable[index]
+		t.Run(fmt.Sprintf("%v %v", index, tc.name), func(t *testing.T) {
+			strategies, err := parseGroupStrategies(tc.groupBy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			grouper := newPodGrouper(&PodListResult{groupStrategies: strategies})
+			name, kind := grouper.groupOf(&tc.pod)
+			if name != tc.expectedName {
+				t.Errorf("Invalid group name. Want: %v, Got: %v", tc.expectedName, name)
+			}
+			if kind != tc.expectedKind {
+				t.Errorf("Invalid group kind. Want: %v, Got: %v", tc.expectedKind, kind)
+			}
+		})
+	}
+}
+
+func TestParseGroupStrategiesErrors(t *testing.T) {
+	invalid := []string{"owner:foo", "label", "annotation:", "regex:(", "namespace"}
+	for _, spec := range invalid {
+		if _, err := parseGroupStrategies([]string{spec}); err == nil {
+			t.Errorf("Expected error for '%v'", spec)
+		}
+	}
+}
+
+func fakeMeta(name string, owners ...metav1.OwnerReference) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Name: name, OwnerReferences: owners}
+}
+
+func fakeOwner(kind, name string) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{Kind: kind, Name: name, Controller: &controller}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"sort"
+	"strings"
+)
+
+type podSortColumn int
+
+const (
+	sortByName podSortColumn = iota
+	sortByReady
+	sortByStatus
+	sortByRestarts
+	sortByAge
+	podSortColumnCount
+)
+
+// podSort is the order of pods within pod groups and revisions, pods are ordered by name when sort values are equal.
+type podSort struct {
+	column     podSortColumn
+	descending bool
+}
+
+// indicator is appended to the header of sorted column.
+func (ps podSort) indicator() string {
+	if ps.descending {
+		return "v"
+	}
+	return "^"
+}
+
+func (ps podSort) nextColumn() podSort {
+	return podSort{column: (ps.column + 1) % podSortColumnCount, descending: ps.descending}
+}
+
+func (ps podSort) reversed() podSort {
+	return podSort{column: ps.column, descending: !ps.descending}
+}
+
+func (ps podSort) less(a, b *Pod) bool {
+	var cmp int
+	switch ps.column {
+	case sortByReady:
+		// Ready containers are compared as a fraction of total, so that 1/1 comes after 1/2.
+		cmp = compareInts(a.ready*b.total, b.ready*a.total)
+	case sortByStatus:
+		cmp = strings.Compare(a.status, b.status)
+	case sortByRestarts:
+		cmp = compareInts(a.restarts, b.restarts)
+	case sortByAge:
+		// Age is ascending when the most recently created pod is first.
+		switch {
+		case a.creationTime.After(b.creationTime):
+			cmp = -1
+		case a.creationTime.Before(b.creationTime):
+			cmp = 1
+		}
+	}
+	if cmp == 0 {
+		cmp = strings.Compare(a.name, b.name)
+	}
+	if ps.descending {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+// sortPods sorts items in place, when they are pods.
+func (ps podSort) sortPods(items []Item) {
+	for _, item := range items {
+		if item.Type() != TypePod {
+			return
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return ps.less(items[i].(*Pod), items[j].(*Pod))
+	})
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
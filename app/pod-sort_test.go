@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortPods(t *testing.T) {
+	now := time.Now()
+	pods := []Pod{
+		{name: "b", ready: 1, total: 2, status: "Running", restarts: 3, creationTime: now.Add(-time.Hour)},
+		{name: "a", ready: 2, total: 2, status: "Running", restarts: 0, creationTime: now.Add(-time.Minute)},
+		{name: "c", ready: 0, total: 1, status: "CrashLoopBackOff", restarts: 7, creationTime: now.Add(-time.Second)},
+		{name: "d", ready: 1, total: 1, status: "Running", restarts: 0, creationTime: now.Add(-2 * time.Hour)},
+	}
+
+	testTable := []struct {
+		name          string
+		podSort       podSort
+		expectedNames []string
+	}{
+		{"name", podSort{column: sortByName}, []string{"a", "b", "c", "d"}},
+		{"name_descending", podSort{column: sortByName, descending: true}, []string{"d", "c", "b", "a"}},
+		{"ready", podSort{column: sortByReady}, []string{"c", "b", "a", "d"}},
+		{"status", podSort{column: sortByStatus}, []string{"c", "a", "b", "d"}},
+		{"restarts_descending", podSort{column: sortByRestarts, descending: true}, []string{"c", "b", "d", "a"}},
+		{"age", podSort{column: sortByAge}, []string{"c", "a", "b", "d"}},
+	}
+
+	for index := range testTable {
+		tc := &testTable[index]
+		t.Run(fmt.Sprintf("%v %v", index, tc.name), func(t *testing.T) {
+			items := make([]Item, 0)
+			for pIndex := range pods {
+				items = append(items, &pods[pIndex])
+			}
+			tc.podSort.sortPods(items)
+			names := make([]string, 0)
+			for _, item := range items {
+				names = append(names, itemName(item))
+			}
+			if !reflect.DeepEqual(names, tc.expectedNames) {
+				t.Errorf("Invalid order.\nWant: %q\nGot:  %q", tc.expectedNames, names)
+			}
+		})
+	}
+}
+
+func TestPodSortNextColumn(t *testing.T) {
+	ps := podSort{column: sortByAge, descending: true}.nextColumn()
+	if ps.column != sortByName || !ps.descending {
+		t.Errorf("Invalid next column. Want: %v descending, Got: %+v", sortByName, ps)
+	}
+}
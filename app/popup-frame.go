@@ -1,10 +1,40 @@
 package app
 
-import "github.com/gdamore/tcell"
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"strings"
+)
 
 const (
 	PopupItemXOffset = 2
 	PopupItemYOffset = 2
+	// PopupScreenMargin is the number of rows kept free above and below the popup, longer lists scroll.
+	PopupScreenMargin = 3
+	// PopupInputWidth is the minimum width of text input popups.
+	PopupInputWidth = 40
+)
+
+type popupKind int
+
+const (
+	// popupList selects a single item, items are filtered by typing.
+	popupList popupKind = iota
+	// popupMultiSelect toggles items with Space and returns all checked items.
+	popupMultiSelect
+	// popupInput is a single line text input, which can't be confirmed while validation fails.
+	popupInput
+	// popupConfirm is a No/Yes list, which also accepts y and n keys.
+	popupConfirm
+)
+
+// popupResult tells the caller of handleKey whether the popup is finished.
+type popupResult int
+
+const (
+	popupOpen popupResult = iota
+	popupDone
+	popupCancelled
 )
 
 type PopupFrame struct {
@@ -12,25 +42,79 @@ type PopupFrame struct {
 	width, height int
 	visible       bool
 	title         string
+	kind          popupKind
 	items         []string
-	cursorYPos    int
-	callback      func(string)
+	// filter narrows items down to the ones containing it, filtered are indexes of those items.
+	filter   string
+	filtered []int
+	// cursorYPos is the selected position in filtered items, offset is the first shown one.
+	cursorYPos int
+	offset     int
+	checked    map[int]bool
+	// value is the text of input popups, validate returns an error which is shown below it, it can be nil.
+	value    string
+	validate func(string) error
+	err      error
+	callback func(string)
+	// multiCallback receives checked items of multi select popups in their original order.
+	multiCallback func([]string)
 }
 
 func NewPopupFrame(s tcell.Screen, title string, items []string, callback func(string)) *PopupFrame {
 	popup := &PopupFrame{
 		visible:  false,
 		title:    title,
+		kind:     popupList,
 		items:    items,
 		callback: callback,
 	}
+	popup.applyFilter()
 	popup.resize(s)
 	return popup
 }
 
+// NewMultiSelectPopup returns a list with checkboxes, all items are checked when checkAll is set.
+func NewMultiSelectPopup(s tcell.Screen, title string, items []string, checkAll bool, callback func([]string)) *PopupFrame {
+	popup := NewPopupFrame(s, title, items, nil)
+	popup.kind = popupMultiSelect
+	popup.multiCallback = callback
+	popup.checked = make(map[int]bool)
+	for index := range items {
+		popup.checked[index] = checkAll
+	}
+	popup.resize(s)
+	return popup
+}
+
+// NewInputPopup returns a text input with initial value, callback is called only with a valid value.
+func NewInputPopup(s tcell.Screen, title, value string, validate func(string) error, callback func(string)) *PopupFrame {
+	popup := &PopupFrame{
+		title:    title,
+		kind:     popupInput,
+		value:    value,
+		validate: validate,
+		callback: callback,
+	}
+	popup.resize(s)
+	return popup
+}
+
+// NewConfirmPopup returns a No/Yes question, No is selected by default.
+func NewConfirmPopup(s tcell.Screen, title string, callback func(bool)) *PopupFrame {
+	popup := NewPopupFrame(s, title, []string{"No", "Yes"}, func(selected string) {
+		callback(selected == "Yes")
+	})
+	popup.kind = popupConfirm
+	return popup
+}
+
 func (pf *PopupFrame) show(s tcell.Screen) {
 	pf.clear(s)
-	pf.drawItems(s)
+	if pf.kind == popupInput {
+		pf.drawInput(s)
+	} else {
+		pf.drawItems(s)
+	}
 	pf.drawBorder(s)
 }
 
@@ -42,22 +126,169 @@ func (pf *PopupFrame) clear(s tcell.Screen) {
 	}
 }
 
-func (pf *PopupFrame) moveCursorDown(s tcell.Screen) {
-	if pf.cursorYPos < len(pf.items)-1 {
-		pf.cursorYPos++
-	} else {
-		pf.cursorYPos = len(pf.items) - 1
+// handleKey applies key event to the popup, callbacks are called by done, after the popup is hidden.
+func (pf *PopupFrame) handleKey(ev *tcell.EventKey) popupResult {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return popupCancelled
+	case tcell.KeyEnter:
+		if pf.kind == popupInput {
+			pf.err = pf.validateValue()
+			if pf.err != nil {
+				return popupOpen
+			}
+			return popupDone
+		}
+		if len(pf.filtered) == 0 {
+			return popupOpen
+		}
+		return popupDone
+	}
+	if pf.kind == popupInput {
+		pf.handleInputKey(ev)
+		return popupOpen
+	}
+
+	switch ev.Key() {
+	case tcell.KeyDown:
+		pf.moveCursor(1)
+	case tcell.KeyUp:
+		pf.moveCursor(-1)
+	case tcell.KeyPgDn:
+		pf.moveCursor(pf.rowsHeight())
+	case tcell.KeyPgUp:
+		pf.moveCursor(-pf.rowsHeight())
+	case tcell.KeyHome:
+		pf.moveCursor(-len(pf.filtered))
+	case tcell.KeyEnd:
+		pf.moveCursor(len(pf.filtered))
+	case tcell.KeyCtrlA:
+		if pf.kind == popupMultiSelect {
+			pf.toggleAll()
+		}
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if pf.filter != "" {
+			runes := []rune(pf.filter)
+			pf.filter = string(runes[:len(runes)-1])
+			pf.applyFilter()
+		}
+	case tcell.KeyRune:
+		return pf.handleRune(ev.Rune())
 	}
-	pf.drawItems(s)
+	return popupOpen
 }
 
-func (pf *PopupFrame) moveCursorUp(s tcell.Screen) {
-	if pf.cursorYPos > 0 {
-		pf.cursorYPos--
-	} else {
+func (pf *PopupFrame) handleRune(r rune) popupResult {
+	switch {
+	case pf.kind == popupConfirm && (r == 'y' || r == 'n'):
 		pf.cursorYPos = 0
+		if r == 'y' {
+			pf.cursorYPos = 1
+		}
+		return popupDone
+	case pf.kind == popupConfirm:
+		return popupOpen
+	case pf.kind == popupMultiSelect && r == ' ':
+		if len(pf.filtered) > 0 {
+			index := pf.filtered[pf.cursorYPos]
+			pf.checked[index] = !pf.checked[index]
+		}
+		return popupOpen
+	}
+	pf.filter += string(r)
+	pf.applyFilter()
+	return popupOpen
+}
+
+func (pf *PopupFrame) handleInputKey(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if pf.value == "" {
+			return
+		}
+		runes := []rune(pf.value)
+		pf.value = string(runes[:len(runes)-1])
+	case tcell.KeyRune:
+		pf.value += string(ev.Rune())
+	default:
+		return
+	}
+	pf.err = pf.validateValue()
+}
+
+func (pf *PopupFrame) validateValue() error {
+	if pf.validate == nil {
+		return nil
 	}
-	pf.drawItems(s)
+	return pf.validate(pf.value)
+}
+
+// done calls the callback with the result, it is called when handleKey returns popupDone.
+func (pf *PopupFrame) done() {
+	switch pf.kind {
+	case popupInput:
+		pf.callback(pf.value)
+	case popupMultiSelect:
+		pf.multiCallback(pf.checkedItems())
+	default:
+		pf.callback(pf.selected())
+	}
+}
+
+// selected returns the item under cursor, or an empty string when no item matches the filter.
+func (pf *PopupFrame) selected() string {
+	if len(pf.filtered) == 0 {
+		return ""
+	}
+	return pf.items[pf.filtered[pf.cursorYPos]]
+}
+
+// checkedItems returns checked items, including the ones hidden by the filter.
+func (pf *PopupFrame) checkedItems() []string {
+	result := make([]string, 0)
+	for index, item := range pf.items {
+		if pf.checked[index] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// toggleAll checks all shown items, or unchecks them when all are already checked.
+func (pf *PopupFrame) toggleAll() {
+	allChecked := true
+	for _, index := range pf.filtered {
+		allChecked = allChecked && pf.checked[index]
+	}
+	for _, index := range pf.filtered {
+		pf.checked[index] = !allChecked
+	}
+}
+
+func (pf *PopupFrame) applyFilter() {
+	pf.filtered = make([]int, 0)
+	query := strings.ToLower(pf.filter)
+	for index, item := range pf.items {
+		if strings.Contains(strings.ToLower(item), query) {
+			pf.filtered = append(pf.filtered, index)
+		}
+	}
+	pf.cursorYPos, pf.offset = 0, 0
+}
+
+func (pf *PopupFrame) moveCursor(n int) {
+	pf.cursorYPos = clampInt(pf.cursorYPos+n, 0, maxInt(len(pf.filtered)-1, 0))
+	if pf.cursorYPos < pf.offset {
+		pf.offset = pf.cursorYPos
+	}
+	if pf.cursorYPos >= pf.offset+pf.rowsHeight() {
+		pf.offset = pf.cursorYPos - pf.rowsHeight() + 1
+	}
+}
+
+// rowsHeight is the number of rows available for items or for the input and its error.
+func (pf *PopupFrame) rowsHeight() int {
+	return pf.height - 2
 }
 
 func (pf *PopupFrame) drawBorder(s tcell.Screen) {
@@ -71,35 +302,89 @@ func (pf *PopupFrame) drawBorder(s tcell.Screen) {
 	}
 
 	draw(s, pf.title, pf.x+3, pf.y, len(pf.title), tcell.StyleDefault)
+	if pf.kind == popupInput {
+		return
+	}
+	// Filter and scroll position are shown in the bottom border.
+	footer := ""
+	if pf.filter != "" {
+		footer = "/" + pf.filter + " "
+	}
+	if len(pf.filtered) > pf.rowsHeight() {
+		footer += fmt.Sprintf("%d/%d ", pf.cursorYPos+1, len(pf.filtered))
+	}
+	if footer != "" {
+		drawRunes(s, " "+footer, pf.x+3, pf.y+pf.height, pf.x+pf.width, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+	}
 }
 
 func (pf *PopupFrame) drawItems(s tcell.Screen) {
-	for index, item := range pf.items {
+	itemWidth := pf.width - PopupItemXOffset - 1
+	for row := 0; row < pf.rowsHeight(); row++ {
+		y := pf.y + PopupItemYOffset + row
+		position := pf.offset + row
+		if position >= len(pf.filtered) {
+			draw(s, "", pf.x+PopupItemXOffset, y, itemWidth, tcell.StyleDefault)
+			continue
+		}
 		style := tcell.StyleDefault
-		if pf.cursorYPos == index {
+		if pf.cursorYPos == position {
 			style = tcell.StyleDefault.Reverse(true)
 		}
-		draw(s, item, pf.x+PopupItemXOffset, pf.y+PopupItemYOffset+index, len(item), style)
+		index := pf.filtered[position]
+		item := pf.items[index]
+		if pf.kind == popupMultiSelect {
+			item = checkbox(pf.checked[index]) + item
+		}
+		draw(s, item, pf.x+PopupItemXOffset, y, itemWidth, style)
 	}
 }
 
+func (pf *PopupFrame) drawInput(s tcell.Screen) {
+	itemWidth := pf.width - PopupItemXOffset - 1
+	value := "> " + pf.value
+	// Beginning of a long value is scrolled out, so that the end with the cursor is shown.
+	if runes := []rune(value); len(runes) >= itemWidth {
+		value = string(runes[len(runes)-itemWidth+1:])
+	}
+	x := drawRunes(s, value, pf.x+PopupItemXOffset, pf.y+PopupItemYOffset, pf.x+PopupItemXOffset+itemWidth, tcell.StyleDefault)
+	s.ShowCursor(x, pf.y+PopupItemYOffset)
+	if pf.err != nil {
+		drawRunes(s, pf.err.Error(), pf.x+PopupItemXOffset, pf.y+PopupItemYOffset+1, pf.x+PopupItemXOffset+itemWidth,
+			tcell.StyleDefault.Foreground(tcell.ColorRed))
+	}
+}
+
+func checkbox(checked bool) string {
+	if checked {
+		return "[x] "
+	}
+	return "[ ] "
+}
+
 func (pf *PopupFrame) resize(s tcell.Screen) {
-	frameHeight := len(pf.items) + 2
+	sw, sh := s.Size()
 	w := len(pf.title) + 2
-	for _, item := range pf.items {
-		length := len(item)
-		if length > w {
-			w = length
+	rows := 2
+	if pf.kind == popupInput {
+		w = maxInt(w, PopupInputWidth)
+	} else {
+		for _, item := range pf.items {
+			length := len(item)
+			if pf.kind == popupMultiSelect {
+				length += len(checkbox(false))
+			}
+			if length > w {
+				w = length
+			}
 		}
+		rows = clampInt(len(pf.items), 1, maxInt(sh-2*PopupScreenMargin, 1))
 	}
 
-	pf.height = frameHeight
-	pf.width = w + 3
-
-	sw, sh := s.Size()
-	newX := (sw - pf.width) / 2
-	newY := (sh - pf.height) / 2
+	pf.height = rows + 2
+	pf.width = minInt(w+3, maxInt(sw-1, 1))
 
-	pf.x = newX
-	pf.y = newY
+	pf.x = (sw - pf.width) / 2
+	pf.y = (sh - pf.height) / 2
+	pf.moveCursor(0)
 }
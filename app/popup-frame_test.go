@@ -0,0 +1,142 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gdamore/tcell"
+	"reflect"
+	"testing"
+)
+
+func popupScreen(width, height int) tcell.SimulationScreen {
+	screen := tcell.NewSimulationScreen("")
+	_ = screen.Init()
+	screen.SetSize(width, height)
+	return screen
+}
+
+func typeKeys(pf *PopupFrame, keys ...interface{}) popupResult {
+	result := popupOpen
+	for _, key := range keys {
+		switch key := key.(type) {
+		case string:
+			for _, r := range key {
+				result = pf.handleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+			}
+		case tcell.Key:
+			result = pf.handleKey(tcell.NewEventKey(key, 0, tcell.ModNone))
+		}
+	}
+	return result
+}
+
+func TestPopupScrolling(t *testing.T) {
+	items := make([]string, 0)
+	for i := 0; i < 30; i++ {
+		items = append(items, fmt.Sprintf("pod-%02d", i))
+	}
+	screen := popupScreen(80, 16)
+	pf := NewPopupFrame(screen, "Pods", items, nil)
+	if pf.rowsHeight() != 10 {
+		t.Fatalf("Invalid rows height. Want: 10, Got: %v", pf.rowsHeight())
+	}
+	typeKeys(pf, tcell.KeyPgDn, tcell.KeyDown, tcell.KeyDown)
+	if pf.cursorYPos != 12 || pf.offset != 3 {
+		t.Errorf("Invalid position. Want: 12/3, Got: %v/%v", pf.cursorYPos, pf.offset)
+	}
+	typeKeys(pf, tcell.KeyHome)
+	if pf.cursorYPos != 0 || pf.offset != 0 {
+		t.Errorf("Invalid position. Want: 0/0, Got: %v/%v", pf.cursorYPos, pf.offset)
+	}
+	typeKeys(pf, tcell.KeyEnd)
+	if pf.cursorYPos != 29 || pf.offset != 20 {
+		t.Errorf("Invalid position. Want: 29/20, Got: %v/%v", pf.cursorYPos, pf.offset)
+	}
+	pf.show(screen)
+	first := ""
+	for x := 0; x < len("pod-20"); x++ {
+		r, _, _, _ := screen.GetContent(pf.x+PopupItemXOffset+x, pf.y+PopupItemYOffset)
+		first += string(r)
+	}
+	if first != "pod-20" {
+		t.Errorf("Invalid first shown item. Want: pod-20, Got: %v", first)
+	}
+}
+
+func TestPopupFilter(t *testing.T) {
+	selected := ""
+	pf := NewPopupFrame(popupScreen(80, 24), "Container", []string{"app", "istio-proxy", "log-shipper"}, func(value string) {
+		selected = value
+	})
+	typeKeys(pf, "I")
+	if !reflect.DeepEqual(pf.filtered, []int{1, 2}) {
+		t.Errorf("Invalid filtered items: %v", pf.filtered)
+	}
+	typeKeys(pf, "x")
+	if result := typeKeys(pf, tcell.KeyEnter); result != popupOpen {
+		t.Errorf("Popup without matching items should stay open")
+	}
+	if result := typeKeys(pf, tcell.KeyBackspace2, tcell.KeyDown, tcell.KeyEnter); result != popupDone {
+		t.Fatalf("Invalid result: %v", result)
+	}
+	pf.done()
+	if selected != "log-shipper" {
+		t.Errorf("Invalid selected item. Want: log-shipper, Got: %v", selected)
+	}
+}
+
+func TestPopupMultiSelect(t *testing.T) {
+	var selected []string
+	pf := NewMultiSelectPopup(popupScreen(80, 24), "Pods", []string{"web-1", "web-2", "db-0"}, true, func(values []string) {
+		selected = values
+	})
+	typeKeys(pf, " ", "db", tcell.KeyCtrlA, tcell.KeyCtrlA, tcell.KeyEnter)
+	pf.done()
+	if !reflect.DeepEqual(selected, []string{"web-2", "db-0"}) {
+		t.Errorf("Invalid selected items: %v", selected)
+	}
+}
+
+func TestPopupInput(t *testing.T) {
+	value := ""
+	validate := func(value string) error {
+		if value != "web" {
+			return errors.New("type web to confirm")
+		}
+		return nil
+	}
+	pf := NewInputPopup(popupScreen(80, 24), "Type web", "", validate, func(v string) { value = v })
+	if result := typeKeys(pf, "wb", tcell.KeyEnter); result != popupOpen || pf.err == nil {
+		t.Errorf("Invalid value should not be confirmed, Got: %v %v", result, pf.err)
+	}
+	if result := typeKeys(pf, tcell.KeyBackspace2, "eb", tcell.KeyEnter); result != popupDone {
+		t.Fatalf("Invalid result: %v, %v", result, pf.err)
+	}
+	pf.done()
+	if value != "web" {
+		t.Errorf("Invalid value. Want: web, Got: %v", value)
+	}
+}
+
+func TestPopupConfirm(t *testing.T) {
+	testTable := []struct {
+		keys     []interface{}
+		expected bool
+	}{
+		{[]interface{}{tcell.KeyEnter}, false},
+		{[]interface{}{tcell.KeyDown, tcell.KeyEnter}, true},
+		{[]interface{}{"y"}, true},
+		{[]interface{}{tcell.KeyDown, "n"}, false},
+	}
+	for index, tc := range testTable {
+		confirmed := !tc.expected
+		pf := NewConfirmPopup(popupScreen(80, 24), "Delete?", func(value bool) { confirmed = value })
+		if result := typeKeys(pf, tc.keys...); result != popupDone {
+			t.Fatalf("%v: invalid result: %v", index, result)
+		}
+		pf.done()
+		if confirmed != tc.expected {
+			t.Errorf("%v: Want: %v, Got: %v", index, tc.expected, confirmed)
+		}
+	}
+}
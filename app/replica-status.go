@@ -0,0 +1,107 @@
+package app
+
+import (
+	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// replicaStatus holds replica counts of a Deployment, StatefulSet or DaemonSet.
+type replicaStatus struct {
+	desired   int32
+	updated   int32
+	ready     int32
+	available int32
+}
+
+func deploymentReplicaStatus(deployment *appsv1.Deployment) *replicaStatus {
+	return &replicaStatus{
+		desired:   replicasOrDefault(deployment.Spec.Replicas),
+		updated:   deployment.Status.UpdatedReplicas,
+		ready:     deployment.Status.ReadyReplicas,
+		available: deployment.Status.AvailableReplicas,
+	}
+}
+
+func statefulSetReplicaStatus(statefulSet *appsv1.StatefulSet) *replicaStatus {
+	// StatefulSets don't report available replicas, ready pods are available as soon as they are ready.
+	return &replicaStatus{
+		desired:   replicasOrDefault(statefulSet.Spec.Replicas),
+		updated:   statefulSet.Status.UpdatedReplicas,
+		ready:     statefulSet.Status.ReadyReplicas,
+		available: statefulSet.Status.ReadyReplicas,
+	}
+}
+
+func daemonSetReplicaStatus(daemonSet *appsv1.DaemonSet) *replicaStatus {
+	return &replicaStatus{
+		desired:   daemonSet.Status.DesiredNumberScheduled,
+		updated:   daemonSet.Status.UpdatedNumberScheduled,
+		ready:     daemonSet.Status.NumberReady,
+		available: daemonSet.Status.NumberAvailable,
+	}
+}
+
+// replicasOrDefault returns spec replicas, which default to 1 when they are not set.
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// isHealthy reports whether all desired replicas are ready, so a controller without pods is flagged only when it wants
+// some, a controller scaled to 0 is healthy.
+func (rs *replicaStatus) isHealthy() bool {
+	return rs.ready >= rs.desired && rs.available >= rs.desired
+}
+
+func (rs *replicaStatus) String() string {
+	return fmt.Sprintf("desired %d, updated %d, ready %d, available %d", rs.desired, rs.updated, rs.ready, rs.available)
+}
+
+// applyReplicaStatuses adds replica statuses to pod groups owned by controllers. Controllers without any pods get an
+// empty pod group when pods are grouped by owner, so that e.g. a Deployment which fails to create pods is still shown.
+// Controllers of pods which are grouped by another strategy, e.g. a label which goes before owner, don't get one.
+func applyReplicaStatuses(podGroups []*PodGroup, plr *PodListResult, grouper podGrouper, parent *Namespace) []*PodGroup {
+	type controller struct{ kind, name string }
+	statuses := make(map[controller]*replicaStatus)
+	for index := range plr.deployments {
+		statuses[controller{KindDeployment, plr.deployments[index].Name}] = deploymentReplicaStatus(&plr.deployments[index])
+	}
+	for index := range plr.statefulSets {
+		statuses[controller{KindStatefulSet, plr.statefulSets[index].Name}] = statefulSetReplicaStatus(&plr.statefulSets[index])
+	}
+	for index := range plr.daemonSets {
+		statuses[controller{KindDaemonSet, plr.daemonSets[index].Name}] = daemonSetReplicaStatus(&plr.daemonSets[index])
+	}
+
+	for _, pg := range podGroups {
+		key := controller{pg.kind, pg.name}
+		if status, ok := statuses[key]; ok {
+			pg.replicas = status
+			delete(statuses, key)
+		}
+	}
+
+	if !hasStrategy(plr.groupStrategies, StrategyOwner) {
+		return podGroups
+	}
+	for index := range plr.Items {
+		pod := &plr.Items[index]
+		if ref := metav1.GetControllerOf(pod); ref != nil {
+			name, kind := grouper.topLevelOwner(pod, ref)
+			delete(statuses, controller{kind, name})
+		}
+	}
+	for key, status := range statuses {
+		podGroups = append(podGroups, &PodGroup{
+			name:      key.name,
+			kind:      key.kind,
+			pods:      make([]Pod, 0),
+			namespace: parent,
+			replicas:  status,
+		})
+	}
+	return podGroups
+}
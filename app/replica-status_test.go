@@ -0,0 +1,107 @@
+package app
+
+import (
+	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"testing"
+)
+
+func TestApplyReplicaStatuses(t *testing.T) {
+	three := int32(3)
+	zero := int32(0)
+	plr := PodListResult{
+		PodList: v1.PodList{Items: []v1.Pod{
+			{ObjectMeta: fakeMeta("db-0", fakeOwner(KindStatefulSet, "db"))},
+		}},
+		deployments: []appsv1.Deployment{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+				Spec:       appsv1.DeploymentSpec{Replicas: &three},
+				Status:     appsv1.DeploymentStatus{UpdatedReplicas: 3},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "idle"},
+				Spec:       appsv1.DeploymentSpec{Replicas: &zero},
+			},
+		},
+		statefulSets: []appsv1.StatefulSet{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "db"},
+				Status:     appsv1.StatefulSetStatus{UpdatedReplicas: 1, ReadyReplicas: 1},
+			},
+		},
+	}
+	strategies, err := parseGroupStrategies(defaultGroupBy(DefaultPodGroupLabels))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plr.groupStrategies = strategies
+
+	podGroups := toPodGroup(&plr, &Namespace{name: "ns"})
+	if len(podGroups) != 3 {
+		t.Fatalf("Invalid pod group count. Want: 3, Got: %v", len(podGroups))
+	}
+
+	testTable := []struct {
+		podGroup        *PodGroup
+		expectedName    string
+		expectedStatus  string
+		expectedHealthy bool
+	}{
+		{podGroups[0], "db", "desired 1, updated 1, ready 1, available 1", true},
+		{podGroups[1], "idle", "desired 0, updated 0, ready 0, available 0", true},
+		{podGroups[2], "web", "desired 3, updated 3, ready 0, available 0", false},
+	}
+	for _, tc := range testTable {
+		if tc.podGroup.name != tc.expectedName {
+			t.Errorf("Invalid pod group. Want: %v, Got: %v", tc.expectedName, tc.podGroup.name)
+		}
+		if tc.podGroup.statusInfo() != tc.expectedStatus {
+			t.Errorf("Invalid status of %v. Want: %v, Got: %v", tc.expectedName, tc.expectedStatus, tc.podGroup.statusInfo())
+		}
+		if tc.podGroup.isHealthy() != tc.expectedHealthy {
+			t.Errorf("Invalid health of %v. Want: %v, Got: %v", tc.expectedName, tc.expectedHealthy, tc.podGroup.isHealthy())
+		}
+	}
+
+	plr.groupStrategies, _ = parseGroupStrategies([]string{"label:app"})
+	if podGroups := toPodGroup(&plr, &Namespace{name: "ns"}); len(podGroups) != 1 {
+		t.Errorf("Controllers without pods should be shown only when grouping by owner, Got: %v groups", len(podGroups))
+	}
+}
+
+func TestApplyReplicaStatusesLabelBeforeOwner(t *testing.T) {
+	meta := fakeMeta("web-1", fakeOwner(KindReplicaSet, "web-5d8f"))
+	meta.Labels = map[string]string{"app.kubernetes.io/name": "web"}
+	plr := PodListResult{
+		PodList: v1.PodList{Items: []v1.Pod{{ObjectMeta: meta}}},
+		replicaSets: []appsv1.ReplicaSet{
+			{ObjectMeta: fakeMeta("web-5d8f", fakeOwner(KindDeployment, "web"))},
+		},
+		deployments: []appsv1.Deployment{
+			{ObjectMeta: metav1.ObjectMeta{Name: "web"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "broken"}},
+		},
+	}
+	plr.groupStrategies, _ = parseGroupStrategies([]string{"label:app.kubernetes.io/name", "owner"})
+
+	podGroups := toPodGroup(&plr, &Namespace{name: "ns"})
+	names := make([]string, 0)
+	for _, pg := range podGroups {
+		names = append(names, fmt.Sprintf("%v/%v %d", pg.kind, pg.name, len(pg.pods)))
+	}
+	expected := []string{"Deployment/broken 0", "/web 1"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Invalid pod groups.\nWant: %q\nGot:  %q", expected, names)
+	}
+}
+
+func TestGatherContainerInfosWithoutPods(t *testing.T) {
+	pg := &PodGroup{name: "web", kind: KindDeployment, pods: make([]Pod, 0), namespace: &Namespace{name: "ns"}}
+	if ns, podNames, contNames := gatherContainerInfos(pg); ns != nil || podNames != nil || contNames != nil {
+		t.Errorf("Pod group without pods should have no containers, Got: %v %v %v", ns, podNames, contNames)
+	}
+}
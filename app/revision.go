@@ -0,0 +1,131 @@
+package app
+
+import (
+	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// revisionAnnotation is set by Deployment controller on every ReplicaSet it owns.
+const revisionAnnotation = "deployment.kubernetes.io/revision"
+
+// Revision is a set of Deployment pods which share the same pod-template-hash, i.e. belong to the same ReplicaSet.
+type Revision struct {
+	// number is ReplicaSet's revision, 0 when the ReplicaSet is unknown.
+	number int64
+	hash   string
+	// replicaSet name, empty when the ReplicaSet is unknown.
+	replicaSet string
+	images     []string
+	isCurrent  bool
+	pods       []*Pod
+	isExpanded bool
+	podGroup   *PodGroup
+}
+
+func (r *Revision) Type() Type {
+	return TypeRevision
+}
+
+func (r *Revision) Expanded(b bool) {
+	r.isExpanded = b
+}
+
+func (r *Revision) IsExpanded() bool {
+	return r.isExpanded
+}
+
+func (r *Revision) DisplayName() string {
+	name := "revision ?"
+	if r.number > 0 {
+		name = fmt.Sprintf("revision %d", r.number)
+	}
+	if len(r.images) > 0 {
+		name += " (" + strings.Join(r.images, ", ") + ")"
+	}
+	return name
+}
+
+func (r *Revision) countReadyPods() int {
+	ready := 0
+	for _, pod := range r.pods {
+		if pod.ready == pod.total {
+			ready++
+		}
+	}
+	return ready
+}
+
+func (r *Revision) podNames() []string {
+	names := make([]string, 0)
+	for _, pod := range r.pods {
+		names = append(names, pod.name)
+	}
+	return names
+}
+
+// applyRevisions splits pods of Deployments which are in the middle of a rollout into revisions, newest first.
+// Groups with pods of a single revision are left as they are.
+func applyRevisions(podGroups []*PodGroup, plr *PodListResult) {
+	for _, pg := range podGroups {
+		if pg.kind != KindDeployment {
+			continue
+		}
+		revisions := make(map[string]*Revision)
+		for index := range pg.pods {
+			pod := &pg.pods[index]
+			revision, ok := revisions[pod.templateHash]
+			if !ok {
+				revision = &Revision{hash: pod.templateHash, isExpanded: true, podGroup: pg}
+				revisions[pod.templateHash] = revision
+			}
+			revision.pods = append(revision.pods, pod)
+			pod.revision = revision
+		}
+		if len(revisions) < 2 {
+			for index := range pg.pods {
+				pg.pods[index].revision = nil
+			}
+			continue
+		}
+
+		for index := range plr.replicaSets {
+			rs := &plr.replicaSets[index]
+			ref := metav1.GetControllerOf(rs)
+			if ref == nil || ref.Kind != KindDeployment || ref.Name != pg.name {
+				continue
+			}
+			if revision, ok := revisions[rs.Labels[appsv1.DefaultDeploymentUniqueLabelKey]]; ok {
+				revision.number, _ = strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+				revision.replicaSet = rs.Name
+				revision.images = imageTags(rs)
+			}
+		}
+
+		pg.revisions = make([]*Revision, 0, len(revisions))
+		for _, revision := range revisions {
+			if len(revision.images) == 0 {
+				revision.images = revision.pods[0].containerVersions()
+			}
+			pg.revisions = append(pg.revisions, revision)
+		}
+		sort.Slice(pg.revisions, func(i, j int) bool {
+			if pg.revisions[i].number != pg.revisions[j].number {
+				return pg.revisions[i].number > pg.revisions[j].number
+			}
+			return pg.revisions[i].hash < pg.revisions[j].hash
+		})
+		pg.revisions[0].isCurrent = pg.revisions[0].number > 0
+	}
+}
+
+func imageTags(rs *appsv1.ReplicaSet) []string {
+	tags := make([]string, 0)
+	for _, container := range rs.Spec.Template.Spec.Containers {
+		tags = append(tags, imageVersion(container.Image))
+	}
+	return tags
+}
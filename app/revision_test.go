@@ -0,0 +1,100 @@
+package app
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"testing"
+)
+
+func TestApplyRevisions(t *testing.T) {
+	five := int32(5)
+	plr := PodListResult{
+		PodList: v1.PodList{Items: []v1.Pod{
+			fakeRevisionPod("web-old-1", "web-old", "old"),
+			fakeRevisionPod("web-new-1", "web-new", "new"),
+			fakeRevisionPod("web-old-2", "web-old", "old"),
+			fakeRevisionPod("web-new-2", "web-new", "new"),
+			fakeRevisionPod("web-old-3", "web-old", "old"),
+		}},
+		replicaSets: []appsv1.ReplicaSet{
+			fakeReplicaSet("web-old", "old", "4", "registry/web:1.0"),
+			fakeReplicaSet("web-new", "new", "5", "registry/web:1.1"),
+		},
+		deployments: []appsv1.Deployment{{
+			ObjectMeta: metav1.ObjectMeta{Name: "web"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &five},
+			Status:     appsv1.DeploymentStatus{UpdatedReplicas: 2},
+		}},
+	}
+	plr.groupStrategies, _ = parseGroupStrategies(defaultGroupBy(DefaultPodGroupLabels))
+
+	podGroups := toPodGroup(&plr, &Namespace{name: "ns"})
+	if len(podGroups) != 1 {
+		t.Fatalf("Invalid pod group count. Want: 1, Got: %v", len(podGroups))
+	}
+	pg := podGroups[0]
+	if len(pg.revisions) != 2 {
+		t.Fatalf("Invalid revision count. Want: 2, Got: %v", len(pg.revisions))
+	}
+
+	current, old := pg.revisions[0], pg.revisions[1]
+	if current.DisplayName() != "revision 5 (1.1)" || !current.isCurrent {
+		t.Errorf("Invalid current revision: %v, current: %v", current.DisplayName(), current.isCurrent)
+	}
+	if old.DisplayName() != "revision 4 (1.0)" || old.isCurrent {
+		t.Errorf("Invalid old revision: %v, current: %v", old.DisplayName(), old.isCurrent)
+	}
+	if !reflect.DeepEqual(current.podNames(), []string{"web-new-1", "web-new-2"}) {
+		t.Errorf("Invalid current revision pods: %v", current.podNames())
+	}
+	if !reflect.DeepEqual(old.podNames(), []string{"web-old-1", "web-old-2", "web-old-3"}) {
+		t.Errorf("Invalid old revision pods: %v", old.podNames())
+	}
+	for index := range pg.pods {
+		if pg.pods[index].revision == nil || pg.pods[index].revision.hash != pg.pods[index].templateHash {
+			t.Errorf("Invalid revision of pod %v", pg.pods[index].name)
+		}
+	}
+	expected := "2/5 updated, desired 5, updated 2, ready 0, available 0"
+	if pg.statusInfo() != expected {
+		t.Errorf("Invalid status. Want: %v, Got: %v", expected, pg.statusInfo())
+	}
+}
+
+func TestApplyRevisionsSingleRevision(t *testing.T) {
+	plr := PodListResult{
+		PodList: v1.PodList{Items: []v1.Pod{
+			fakeRevisionPod("web-1", "web-abc", "abc"),
+			fakeRevisionPod("web-2", "web-abc", "abc"),
+		}},
+		replicaSets: []appsv1.ReplicaSet{fakeReplicaSet("web-abc", "abc", "1", "web:1.0")},
+	}
+	plr.groupStrategies, _ = parseGroupStrategies(defaultGroupBy(DefaultPodGroupLabels))
+
+	pg := toPodGroup(&plr, &Namespace{name: "ns"})[0]
+	if len(pg.revisions) != 0 {
+		t.Errorf("Pods of a single revision should not be split, Got: %v revisions", len(pg.revisions))
+	}
+	for index := range pg.pods {
+		if pg.pods[index].revision != nil {
+			t.Errorf("Pod %v should not have a revision", pg.pods[index].name)
+		}
+	}
+}
+
+func fakeRevisionPod(name, replicaSet, hash string) v1.Pod {
+	meta := fakeMeta(name, fakeOwner(KindReplicaSet, replicaSet))
+	meta.Labels = map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: hash}
+	return v1.Pod{ObjectMeta: meta}
+}
+
+func fakeReplicaSet(name, hash, revision, image string) appsv1.ReplicaSet {
+	meta := fakeMeta(name, fakeOwner(KindDeployment, "web"))
+	meta.Labels = map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: hash}
+	meta.Annotations = map[string]string{revisionAnnotation: revision}
+	rs := appsv1.ReplicaSet{ObjectMeta: meta}
+	rs.Spec.Template.Spec.Containers = []v1.Container{{Name: "web", Image: image}}
+	return rs
+}
@@ -0,0 +1,430 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gdamore/tcell"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// runConcurrency is the maximum number of pods a command runs in at the same time.
+	runConcurrency = 10
+	// runOutputLimit is the number of output bytes kept per pod, the rest is dropped.
+	runOutputLimit = 64 * 1024
+)
+
+// runTimeout stops commands which don't finish in time, so that a hanging pod doesn't keep its connection open.
+var runTimeout = time.Minute
+
+// runResult is the outcome of a command in a single pod, exitCode is -1 when the command could not be run.
+type runResult struct {
+	pod        string
+	done       bool
+	exitCode   int
+	duration   time.Duration
+	output     string
+	isExpanded bool
+}
+
+func (rr runResult) exitText() string {
+	if !rr.done {
+		return "..."
+	}
+	if rr.exitCode < 0 {
+		return "error"
+	}
+	return fmt.Sprintf("%d", rr.exitCode)
+}
+
+func (rr runResult) durationText() string {
+	if !rr.done {
+		return "running"
+	}
+	return rr.duration.Round(time.Millisecond).String()
+}
+
+func (rr runResult) outputLines() []string {
+	return strings.Split(strings.TrimRight(rr.output, "\n"), "\n")
+}
+
+// lockedBuffer collects stdout and stderr of a command, which are written concurrently. Output over the limit is
+// dropped without failing the write, so that the command is not stopped by it.
+type lockedBuffer struct {
+	sync.Mutex
+	buffer    bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (lb *lockedBuffer) Write(p []byte) (int, error) {
+	lb.Lock()
+	defer lb.Unlock()
+	if free := lb.limit - lb.buffer.Len(); len(p) > free {
+		lb.truncated = true
+		lb.buffer.Write(p[:maxInt(free, 0)])
+		return len(p), nil
+	}
+	return lb.buffer.Write(p)
+}
+
+func (lb *lockedBuffer) String() string {
+	lb.Lock()
+	defer lb.Unlock()
+	return lb.buffer.String()
+}
+
+// runRow is a line of the results table, line is -1 for the result itself and index of an output line otherwise.
+type runRow struct {
+	result int
+	line   int
+}
+
+// RunFrame is a full screen table with results of a command which runs in a container of several pods, or on the
+// local machine.
+type RunFrame struct {
+	sync.Mutex
+
+	width, height int
+	visible       bool
+	// target is shown in the title, where the command runs.
+	target      string
+	commandLine string
+	// runCommand runs the command for a result, output is written to the writer. The command is stopped when stopCh
+	// is closed.
+	runCommand func(pod string, output io.Writer, stopCh <-chan struct{}) error
+	results    []runResult
+	// cursor is the index of the selected result, offset is the first shown row.
+	cursor   int
+	offset   int
+	redrawCh chan struct{}
+	// stopCh is closed together with the frame, to stop commands which still run.
+	stopCh chan struct{}
+}
+
+func NewRunFrame(s tcell.Screen, k8Client K8Client, ns *Namespace, podNames []string, container string, shell []string, commandLine string) *RunFrame {
+	command := append(append([]string{}, shell...), "-c", commandLine)
+	runCommand := func(pod string, output io.Writer, stopCh <-chan struct{}) error {
+		options := &v1.PodExecOptions{Container: container, Command: command, Stdout: true, Stderr: true}
+		streams := remotecommand.StreamOptions{Stdout: output, Stderr: output}
+		return k8Client.exec(ns.cluster(), ns.name, pod, options, streams, stopCh)
+	}
+	return newRunFrame(s, fmt.Sprintf("%v -c %v", ns.name, container), commandLine, podNames, runCommand)
+}
+
+// NewLocalRunFrame runs commandLine with sh on the local machine, its single result is named by label.
+func NewLocalRunFrame(s tcell.Screen, label, commandLine string) *RunFrame {
+	runCommand := func(pod string, output io.Writer, stopCh <-chan struct{}) error {
+		cmd := exec.Command("sh", "-c", commandLine)
+		cmd.Stdout, cmd.Stderr = output, output
+		// The command gets its own process group, so that stopping it kills processes started by sh as well, which
+		// would keep the output open otherwise.
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		doneCh := make(chan struct{})
+		defer close(doneCh)
+		go func() {
+			select {
+			case <-stopCh:
+				_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			case <-doneCh:
+			}
+		}()
+		err := cmd.Wait()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return utilexec.CodeExitError{Err: exitErr, Code: exitErr.ExitCode()}
+		}
+		return err
+	}
+	return newRunFrame(s, "local", commandLine, []string{label}, runCommand)
+}
+
+func newRunFrame(s tcell.Screen, target, commandLine string, pods []string, runCommand func(string, io.Writer, <-chan struct{}) error) *RunFrame {
+	width, height := s.Size()
+	rf := &RunFrame{
+		width:       width,
+		height:      height,
+		target:      target,
+		commandLine: commandLine,
+		runCommand:  runCommand,
+		redrawCh:    make(chan struct{}, 1),
+		stopCh:      make(chan struct{}),
+	}
+	for _, pod := range pods {
+		rf.results = append(rf.results, runResult{pod: pod})
+	}
+	return rf
+}
+
+// open shows the frame and runs the command in all pods, results are drawn as they arrive.
+func (rf *RunFrame) open(s tcell.Screen) {
+	rf.visible = true
+	go func() {
+		for range rf.redrawCh {
+			rf.draw(s)
+			s.Show()
+			time.Sleep(logRedrawDelay)
+		}
+	}()
+
+	jobCh := make(chan int)
+	for i := 0; i < runConcurrency && i < len(rf.results); i++ {
+		go func() {
+			for index := range jobCh {
+				rf.run(index)
+			}
+		}()
+	}
+	go func() {
+		for index := range rf.results {
+			jobCh <- index
+		}
+		close(jobCh)
+	}()
+	rf.requestRedraw()
+}
+
+// run runs the command for a result. The command is stopped when it times out or when the frame is closed, its
+// result is then an error with the reason after the output received so far.
+func (rf *RunFrame) run(index int) {
+	output := lockedBuffer{limit: runOutputLimit}
+	stopCh, doneCh := make(chan struct{}), make(chan struct{})
+	reasonCh := make(chan string, 1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		timer := time.NewTimer(runTimeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			reasonCh <- fmt.Sprintf("timed out after %v", runTimeout)
+		case <-rf.stopCh:
+			reasonCh <- "cancelled"
+		case <-doneCh:
+			return
+		}
+		close(stopCh)
+	}()
+
+	var err error
+	start := time.Now()
+	select {
+	case <-rf.stopCh:
+		// The frame was closed before the command started.
+		err = errors.New("not started")
+	default:
+		err = rf.runCommand(rf.results[index].pod, &output, stopCh)
+	}
+	duration := time.Since(start)
+	close(doneCh)
+	wg.Wait()
+
+	exitCode := 0
+	text := output.String()
+	if output.truncated {
+		text += fmt.Sprintf("\n[output truncated to %d KiB]\n", runOutputLimit/1024)
+	}
+	select {
+	case reason := <-reasonCh:
+		err = errors.New(reason)
+	default:
+	}
+	if exitErr, ok := err.(utilexec.ExitError); ok {
+		exitCode = exitErr.ExitStatus()
+	} else if err != nil {
+		exitCode = -1
+		text += err.Error()
+	}
+
+	rf.Lock()
+	result := &rf.results[index]
+	result.done, result.exitCode, result.duration, result.output = true, exitCode, duration, text
+	rf.Unlock()
+	rf.requestRedraw()
+}
+
+// close hides the frame and stops commands which still run.
+func (rf *RunFrame) close() {
+	rf.Lock()
+	defer rf.Unlock()
+	rf.visible = false
+	close(rf.redrawCh)
+	close(rf.stopCh)
+}
+
+func (rf *RunFrame) requestRedraw() {
+	rf.Lock()
+	defer rf.Unlock()
+	if !rf.visible {
+		return
+	}
+	select {
+	case rf.redrawCh <- struct{}{}:
+	default:
+	}
+}
+
+// rowsHeight is the number of rows available for results, first two rows are the title and the table header and
+// last two are help and status bar.
+func (rf *RunFrame) rowsHeight() int {
+	return rf.height - 4
+}
+
+func (rf *RunFrame) rows() []runRow {
+	rows := make([]runRow, 0)
+	for index, result := range rf.results {
+		rows = append(rows, runRow{result: index, line: -1})
+		if result.isExpanded && result.done {
+			for line := range result.outputLines() {
+				rows = append(rows, runRow{result: index, line: line})
+			}
+		}
+	}
+	return rows
+}
+
+// moveCursor selects another result and scrolls, so that the selected result and its expanded output are shown.
+func (rf *RunFrame) moveCursor(n int) {
+	rf.Lock()
+	rf.cursor = clampInt(rf.cursor+n, 0, len(rf.results)-1)
+	rf.scrollToCursor()
+	rf.Unlock()
+	rf.requestRedraw()
+}
+
+func (rf *RunFrame) scrollToCursor() {
+	rows := rf.rows()
+	first, last := 0, 0
+	for index, row := range rows {
+		if row.result == rf.cursor {
+			if row.line < 0 {
+				first = index
+			}
+			last = index
+		}
+	}
+	if last-rf.offset >= rf.rowsHeight() {
+		rf.offset = last - rf.rowsHeight() + 1
+	}
+	if first < rf.offset || last-first >= rf.rowsHeight() {
+		rf.offset = first
+	}
+}
+
+func (rf *RunFrame) toggleExpanded() {
+	rf.Lock()
+	result := &rf.results[rf.cursor]
+	result.isExpanded = !result.isExpanded
+	rf.scrollToCursor()
+	rf.Unlock()
+	rf.requestRedraw()
+}
+
+// selectedOutput returns output of the selected result.
+func (rf *RunFrame) selectedOutput() string {
+	rf.Lock()
+	defer rf.Unlock()
+	return rf.results[rf.cursor].output
+}
+
+// content returns all results as text, with output of every pod below its result.
+func (rf *RunFrame) content() string {
+	rf.Lock()
+	defer rf.Unlock()
+	var builder strings.Builder
+	for _, result := range rf.results {
+		builder.WriteString(fmt.Sprintf("%v exit: %v duration: %v\n", result.pod, result.exitText(), result.durationText()))
+		if result.done {
+			for _, line := range result.outputLines() {
+				builder.WriteString("    " + line + "\n")
+			}
+		}
+	}
+	return builder.String()
+}
+
+func (rf *RunFrame) title() string {
+	done := 0
+	for _, result := range rf.results {
+		if result.done {
+			done++
+		}
+	}
+	return fmt.Sprintf("Run: %v | %v | %d/%d done", rf.target, rf.commandLine, done, len(rf.results))
+}
+
+// columnWidths returns widths of pod, exit and duration columns, output takes the rest of the line.
+func (rf *RunFrame) columnWidths() (int, int, int) {
+	podWidth := len("POD")
+	for _, result := range rf.results {
+		podWidth = maxInt(podWidth, len(result.pod))
+	}
+	return podWidth + ColumnSpacing, len("error") + ColumnSpacing, len("DURATION") + ColumnSpacing
+}
+
+func (rf *RunFrame) draw(s tcell.Screen) {
+	rf.Lock()
+	defer rf.Unlock()
+	if !rf.visible {
+		return
+	}
+	s.HideCursor()
+	podWidth, exitWidth, durationWidth := rf.columnWidths()
+	outputX := podWidth + exitWidth + durationWidth
+	drawS(s, rf.title(), 0, 0, rf.width, tcell.StyleDefault.Reverse(true))
+	header := fmt.Sprintf("%-*v%-*v%-*vOUTPUT", podWidth, "POD", exitWidth, "EXIT", durationWidth, "DURATION")
+	drawS(s, header, 0, 1, rf.width, tcell.StyleDefault.Bold(true))
+
+	rows := rf.rows()
+	for y := 0; y < rf.rowsHeight(); y++ {
+		index := rf.offset + y
+		if index >= len(rows) {
+			drawS(s, "", 0, y+2, rf.width, tcell.StyleDefault)
+			continue
+		}
+		row := rows[index]
+		result := rf.results[row.result]
+		if row.line >= 0 {
+			x := drawRunes(s, strings.Repeat(" ", outputX)+result.outputLines()[row.line], 0, y+2, rf.width, tcell.StyleDefault)
+			drawS(s, "", x, y+2, rf.width-x, tcell.StyleDefault)
+			continue
+		}
+
+		style := tcell.StyleDefault
+		if result.done && result.exitCode != 0 {
+			style = style.Foreground(tcell.ColorRed)
+		}
+		if row.result == rf.cursor {
+			style = style.Reverse(true)
+		}
+		summary := ""
+		if result.done && !result.isExpanded {
+			summary = result.outputLines()[0]
+		}
+		line := fmt.Sprintf("%-*v%-*v%-*v%v", podWidth, result.pod, exitWidth, result.exitText(), durationWidth, result.durationText(), summary)
+		x := drawRunes(s, line, 0, y+2, rf.width, style)
+		drawS(s, "", x, y+2, rf.width-x, style)
+	}
+	help := "Esc = close   Enter = expand/collapse output   y = copy output   Y = copy all results"
+	drawS(s, help, 0, rf.height-2, rf.width, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+}
+
+func (rf *RunFrame) resize(s tcell.Screen) {
+	rf.Lock()
+	rf.width, rf.height = s.Size()
+	rf.scrollToCursor()
+	rf.Unlock()
+	rf.requestRedraw()
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gdamore/tcell"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeRunClient writes output of a pod and fails with its error.
+type fakeRunClient struct {
+	K8Client
+	outputs map[string]string
+	errs    map[string]error
+}
+
+func (c fakeRunClient) exec(cluster clusterKey, namespace, pod string, options *v1.PodExecOptions, streams remotecommand.StreamOptions, stopCh <-chan struct{}) error {
+	if !reflect.DeepEqual(options.Command, []string{"/bin/sh", "-c", "cat /app/version"}) || options.Container != "app" {
+		return fmt.Errorf("invalid command %q in %v", options.Command, options.Container)
+	}
+	_, _ = fmt.Fprint(streams.Stdout, c.outputs[pod])
+	return c.errs[pod]
+}
+
+func TestRunFrameResults(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	_ = screen.Init()
+	client := fakeRunClient{
+		outputs: map[string]string{"web-1": "1.2.0\n", "web-2": "line 1\nline 2\n", "web-3": "not found\n"},
+		errs: map[string]error{
+			"web-3": utilexec.CodeExitError{Err: errors.New("command terminated with exit code 1"), Code: 1},
+			"web-4": errors.New("container not found"),
+		},
+	}
+	pods := []string{"web-1", "web-2", "web-3", "web-4"}
+	rf := NewRunFrame(screen, client, &Namespace{name: "ns", context: "dev"}, pods, "app", []string{"/bin/sh"}, "cat /app/version")
+	rf.open(screen)
+	defer rf.close()
+
+	deadline := time.Now().Add(time.Second)
+	for !runFinished(rf) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	expected := "web-1 exit: 0 duration: 0s\n    1.2.0\n" +
+		"web-2 exit: 0 duration: 0s\n    line 1\n    line 2\n" +
+		"web-3 exit: 1 duration: 0s\n    not found\n" +
+		"web-4 exit: error duration: 0s\n    container not found\n"
+	rf.Lock()
+	for index := range rf.results {
+		rf.results[index].duration = 0
+	}
+	rf.Unlock()
+	if content := rf.content(); content != expected {
+		t.Errorf("Invalid results.\nWant: %q\nGot:  %q", expected, content)
+	}
+	if title := rf.title(); title != "Run: ns -c app | cat /app/version | 4/4 done" {
+		t.Errorf("Invalid title: %v", title)
+	}
+
+	rf.moveCursor(1)
+	rf.toggleExpanded()
+	rows := rf.rows()
+	expectedRows := []runRow{{0, -1}, {1, -1}, {1, 0}, {1, 1}, {2, -1}, {3, -1}}
+	if !reflect.DeepEqual(rows, expectedRows) {
+		t.Errorf("Invalid rows.\nWant: %v\nGot:  %v", expectedRows, rows)
+	}
+	if output := rf.selectedOutput(); output != "line 1\nline 2\n" {
+		t.Errorf("Invalid selected output: %q", output)
+	}
+}
+
+func runFinished(rf *RunFrame) bool {
+	rf.Lock()
+	defer rf.Unlock()
+	for _, result := range rf.results {
+		if !result.done {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLocalRunFrame(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	_ = screen.Init()
+	rf := NewLocalRunFrame(screen, "echo", "echo 'it works'; exit 3")
+	rf.open(screen)
+	defer rf.close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !runFinished(rf) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	rf.Lock()
+	result := rf.results[0]
+	rf.Unlock()
+	if !result.done || result.exitCode != 3 || result.output != "it works\n" {
+		t.Errorf("Invalid result: %+v", result)
+	}
+}
+
+func TestLocalRunFrameTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { runTimeout = timeout }(runTimeout)
+	runTimeout = 100 * time.Millisecond
+	screen := tcell.NewSimulationScreen("")
+	_ = screen.Init()
+	// The background sleep keeps output open unless the whole process group is killed.
+	rf := NewLocalRunFrame(screen, "sleep", "echo started; sleep 10 & sleep 10")
+	rf.open(screen)
+	defer rf.close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !runFinished(rf) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	rf.Lock()
+	result := rf.results[0]
+	rf.Unlock()
+	if !result.done || result.exitCode != -1 || result.output != "started\ntimed out after 100ms" {
+		t.Errorf("Invalid result: %+v", result)
+	}
+}
+
+func TestLockedBufferLimit(t *testing.T) {
+	output := lockedBuffer{limit: 8}
+	for _, text := range []string{"12345", "6789", "0"} {
+		if n, err := output.Write([]byte(text)); n != len(text) || err != nil {
+			t.Errorf("Invalid write of %q: %v, %v", text, n, err)
+		}
+	}
+	if output.String() != "12345678" || !output.truncated {
+		t.Errorf("Invalid output: %q, %v", output.String(), output.truncated)
+	}
+}
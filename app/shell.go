@@ -0,0 +1,75 @@
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+	"strings"
+)
+
+// debugImage is used by the debug container which is offered for containers without a shell.
+const debugImage = "busybox"
+
+// shellCandidates are probed in order of preference, the first one which starts is used by exec actions.
+var shellCandidates = [][]string{{"/bin/bash"}, {"/bin/sh"}, {"/bin/ash"}, {"busybox", "sh"}}
+
+// shellCache keeps probed shells by image, containers of the same image are expected to have the same shells.
+// Images without any shell are kept with an empty shell.
+type shellCache map[string][]string
+
+// apply sets shells of all containers of the item whose image was probed already, items of the main frame are
+// updated only with the frame locked.
+func (sc shellCache) apply(item Item) {
+	walkItems(item, func(item Item) {
+		if c, ok := item.(*Container); ok {
+			if shell, ok := sc[c.image]; ok {
+				c.shell, c.shellProbed = shell, true
+			}
+		}
+	})
+}
+
+// probeShell runs shell candidates with an empty script and returns the first one which succeeds, or an empty shell
+// when none does. Errors which are not caused by a missing shell, e.g. missing permissions, are returned.
+func probeShell(k8Client K8Client, ns *Namespace, pod, container string) ([]string, error) {
+	for _, candidate := range shellCandidates {
+		command := append(append([]string{}, candidate...), "-c", "exit 0")
+		options := &v1.PodExecOptions{Container: container, Command: command, Stdout: true, Stderr: true}
+		streams := remotecommand.StreamOptions{Stdout: ioutil.Discard, Stderr: ioutil.Discard}
+		err := k8Client.exec(ns.cluster(), ns.name, pod, options, streams, nil)
+		if err == nil {
+			return candidate, nil
+		}
+		if !isMissingShell(err) {
+			return nil, err
+		}
+	}
+	return []string{}, nil
+}
+
+// isMissingShell tells whether exec failed because the command could not be started. Container runtimes report it
+// either as a non zero exit code or as an error message.
+func isMissingShell(err error) bool {
+	if _, ok := err.(utilexec.ExitError); ok {
+		return true
+	}
+	message := err.Error()
+	return strings.Contains(message, "executable file not found") || strings.Contains(message, "no such file or directory")
+}
+
+// shellCommand is the shell of the container as a command line, bash is assumed until the shell is probed.
+func (c *Container) shellCommand() string {
+	if len(c.shell) == 0 {
+		return defaultExecShell
+	}
+	return strings.Join(c.shell, " ")
+}
+
+// debugCommand starts an ephemeral container which shares process namespace of the container. Ephemeral containers
+// are not part of the API used by the app, so it can only be offered as a kubectl command.
+func (c *Container) debugCommand() string {
+	ns := c.pod.podGroup.namespace
+	return fmt.Sprintf("%v -n %v debug -it %v --image=%v --target=%v", ns.kubectl(), ns.name, c.pod.name, debugImage, c.name)
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeShellClient fails exec of commands which are not in shells with the error of the image.
+type fakeShellClient struct {
+	K8Client
+	shells []string
+	err    error
+}
+
+func (c fakeShellClient) exec(cluster clusterKey, namespace, pod string, options *v1.PodExecOptions, streams remotecommand.StreamOptions, stopCh <-chan struct{}) error {
+	command := strings.Join(options.Command[:len(options.Command)-2], " ")
+	for _, shell := range c.shells {
+		if shell == command {
+			return nil
+		}
+	}
+	return c.err
+}
+
+func TestProbeShell(t *testing.T) {
+	notFound := errors.New(`OCI runtime exec failed: exec failed: container_linux.go:345: starting container process caused "exec: \"/bin/bash\": stat /bin/bash: no such file or directory": unknown`)
+	testTable := []struct {
+		name          string
+		client        fakeShellClient
+		expectedShell []string
+		expectedErr   bool
+	}{
+		{"bash", fakeShellClient{shells: []string{"/bin/bash", "/bin/sh"}, err: notFound}, []string{"/bin/bash"}, false},
+		{"alpine", fakeShellClient{shells: []string{"/bin/sh", "/bin/ash"}, err: notFound}, []string{"/bin/sh"}, false},
+		{"busybox", fakeShellClient{shells: []string{"busybox sh"}, err: utilexec.CodeExitError{Err: notFound, Code: 126}}, []string{"busybox", "sh"}, false},
+		{"distroless", fakeShellClient{err: notFound}, []string{}, false},
+		{"forbidden", fakeShellClient{err: errors.New("pods \"web-1\" is forbidden")}, nil, true},
+	}
+
+	for index, tc := range testTable {
+		t.Run(fmt.Sprintf("%v %v", index, tc.name), func(t *testing.T) {
+			shell, err := probeShell(tc.client, &Namespace{name: "ns", context: "dev"}, "web-1", "app")
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("Invalid error: %v", err)
+			}
+			if !reflect.DeepEqual(shell, tc.expectedShell) {
+				t.Errorf("Invalid shell. Want: %q, Got: %q", tc.expectedShell, shell)
+			}
+		})
+	}
+}
+
+func TestShellCacheApply(t *testing.T) {
+	namespaces := fakeTree()
+	web1 := &namespaces[0].deployments[0].pods[0]
+	web1.containers = []Container{{name: "app", image: "nginx:1.17", pod: web1}, {name: "proxy", image: "envoy:1.12", pod: web1}}
+
+	shellCache{"nginx:1.17": {"/bin/sh"}, "envoy:1.12": {}}.apply(&namespaces[0])
+
+	app, proxy := web1.containers[0], web1.containers[1]
+	if !app.shellProbed || app.shellCommand() != "/bin/sh" {
+		t.Errorf("Invalid app shell: %v, %q", app.shellProbed, app.shellCommand())
+	}
+	if !proxy.shellProbed || len(proxy.shell) != 0 {
+		t.Errorf("Proxy should have no shell: %v, %q", proxy.shellProbed, proxy.shell)
+	}
+
+	expected := "kubectl --context context -n a exec -it web-1 -c app -- /bin/sh"
+	if command := shortcuts(&web1.containers[0])[1].command; command != expected {
+		t.Errorf("Invalid exec command.\nWant: %q\nGot:  %q", expected, command)
+	}
+	expected = "kubectl --context context -n a debug -it web-1 --image=busybox --target=proxy"
+	if command := proxy.debugCommand(); command != expected {
+		t.Errorf("Invalid debug command.\nWant: %q\nGot:  %q", expected, command)
+	}
+}
+
+func TestInfoFrameSetShell(t *testing.T) {
+	frame := InfoFrame{nsItems: fakeTree()}
+	web1 := &frame.nsItems[0].deployments[0].pods[0]
+	web1.containers = []Container{{name: "app", image: "nginx:1.17", pod: web1}}
+	// Container of a previous refresh, which is not in the frame anymore.
+	stale := &Container{name: "app", image: "nginx:1.17", pod: web1}
+
+	frame.setShell(stale, []string{"/bin/sh"})
+	for _, c := range []*Container{stale, &web1.containers[0]} {
+		if shell, probed := frame.containerShell(c); !probed || !reflect.DeepEqual(shell, []string{"/bin/sh"}) {
+			t.Errorf("Invalid shell: %v, %q", probed, shell)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// shortcut is a key which copies a command to clipboard, or runs it for actions from config.json.
+type shortcut struct {
+	key     rune
+	label   string
+	command string
+	// mode is one of action modes, empty for built in shortcuts which are copied.
+	mode string
+	// err is set when action template could not be rendered for the item.
+	err error
+	// container is set for exec commands, which use its shell once it is probed, or bash before that.
+	container *Container
+	// mutation is set for shortcuts which change a resource through the API instead of copying a command.
+	mutation *mutation
+	// mutating shortcuts change resources or run commands in containers, they are left out in read-only mode.
+	mutating bool
+}
+
+func (sc shortcut) String() string {
+	return fmt.Sprintf("%c = %v", sc.key, sc.label)
+}
+
+// keyHelp is footer help of a key handled in the key event loop, mutating keys are disabled in read-only mode.
+type keyHelp struct {
+	text     string
+	mutating bool
+}
+
+// ctrlShortcuts are handled in the key event loop, they are listed here only to be shown in the footer.
+var ctrlShortcuts = []keyHelp{
+	{"Ctrl+E = exec to all", true},
+	{"Ctrl+L = logs from all", false},
+	{"Ctrl+K = follow logs from all", false},
+	{"l = view logs", false},
+	{"r = run in all", true},
+}
+
+var (
+	// workloadKinds are owner kinds which shortcuts can act on, pods owned by anything else, e.g. static pods owned by
+	// a Node, get the same shortcuts as pods grouped by labels.
+	workloadKinds = map[string]bool{
+		KindDeployment: true, KindStatefulSet: true, KindDaemonSet: true, KindReplicaSet: true, KindJob: true, KindCronJob: true,
+	}
+	scalableKinds    = map[string]bool{KindDeployment: true, KindStatefulSet: true, KindReplicaSet: true}
+	restartableKinds = map[string]bool{KindDeployment: true, KindStatefulSet: true, KindDaemonSet: true}
+)
+
+// shortcuts returns number key shortcuts available for the item. Every shortcut keeps its key whether or not the ones
+// before it are available for the item, so e.g. Pod scale is always 5.
+func shortcuts(item Item) []shortcut {
+	result := make([]shortcut, 0)
+	add := func(key rune, label, command string) {
+		result = append(result, shortcut{key: key, label: label, command: command})
+	}
+	addMutating := func(key rune, label, command string) {
+		result = append(result, shortcut{key: key, label: label, command: command, mutating: true})
+	}
+	addMutation := func(key rune, label string, m mutation) {
+		result = append(result, shortcut{key: key, label: label, mutation: &m, mutating: true})
+	}
+
+	switch item.Type() {
+	case TypeNamespace:
+		ns := item.(*Namespace)
+		kubectl := fmt.Sprintf("%v -n %v", ns.kubectl(), ns.name)
+		add('1', "get all", kubectl+" get all")
+		add('2', "get ingress", kubectl+" get ingress")
+		add('3', "get events", kubectl+" get ev --sort-by=.lastTimestamp")
+		add('4', "describe", fmt.Sprintf("%v describe ns %v", ns.kubectl(), ns.name))
+		add('5', "get secrets", kubectl+" get secrets")
+		add('6', "get config map", kubectl+" get cm")
+	case TypePodGroup:
+		pg := item.(*PodGroup)
+		kubectl := fmt.Sprintf("%v -n %v", pg.namespace.kubectl(), pg.namespace.name)
+		if !workloadKinds[pg.kind] {
+			// Pods were grouped by something else than a workload, so there is no resource to act on.
+			add('1', "get pods", fmt.Sprintf("%v get pods %v", kubectl, strings.Join(pg.podNames(), " ")))
+			break
+		}
+		resource := pg.resource()
+		add('1', "describe", fmt.Sprintf("%v describe %v", kubectl, resource))
+		addMutation('2', "delete "+pg.kindName(), mutation{verb: mutationDelete, namespace: pg.namespace, kind: pg.kind, name: pg.name})
+		if scalableKinds[pg.kind] {
+			addMutation('3', "scale", mutation{verb: mutationScale, namespace: pg.namespace, kind: pg.kind, name: pg.name, replicas: podGroupReplicas(pg)})
+		}
+		if restartableKinds[pg.kind] {
+			addMutating('4', "rollout restart", fmt.Sprintf("%v rollout restart %v", kubectl, resource))
+		}
+		if pg.kind == KindCronJob {
+			addMutating('5', "trigger job", fmt.Sprintf("%v create job --from=%v %v-manual", kubectl, resource, pg.name))
+		}
+	case TypeRevision:
+		revision := item.(*Revision)
+		ns := revision.podGroup.namespace
+		kubectl := fmt.Sprintf("%v -n %v", ns.kubectl(), ns.name)
+		add('1', "get pods", fmt.Sprintf("%v get pods %v", kubectl, strings.Join(revision.podNames(), " ")))
+		if revision.replicaSet != "" {
+			add('2', "describe replica set", fmt.Sprintf("%v describe replicaset/%v", kubectl, revision.replicaSet))
+		}
+		add('3', "rollout status", fmt.Sprintf("%v rollout status %v", kubectl, revision.podGroup.resource()))
+		addMutating('4', "rollout undo", fmt.Sprintf("%v rollout undo %v", kubectl, revision.podGroup.resource()))
+	case TypePod:
+		pod := item.(*Pod)
+		pg := pod.podGroup
+		kubectl := fmt.Sprintf("%v -n %v", pg.namespace.kubectl(), pg.namespace.name)
+		add('1', "get logs", fmt.Sprintf("%v logs %v", kubectl, pod.name))
+		if len(pod.containers) > 0 {
+			// Default container of kubectl exec is the first one.
+			cont := &pod.containers[0]
+			addMutating('2', "exec", fmt.Sprintf("%v exec -it %v -- %v", kubectl, pod.name, cont.shellCommand()))
+			result[len(result)-1].container = cont
+		} else {
+			addMutating('2', "exec", fmt.Sprintf("%v exec -it %v -- %v", kubectl, pod.name, defaultExecShell))
+		}
+		add('3', "describe", fmt.Sprintf("%v describe pod %v", kubectl, pod.name))
+		addMutation('4', "delete pod", mutation{verb: mutationDelete, namespace: pg.namespace, kind: KindPod, name: pod.name})
+		if scalableKinds[pg.kind] {
+			addMutation('5', "scale", mutation{verb: mutationScale, namespace: pg.namespace, kind: pg.kind, name: pg.name, replicas: podGroupReplicas(pg)})
+		}
+	case TypeContainer:
+		cont := item.(*Container)
+		ns := cont.pod.podGroup.namespace
+		kubectl := fmt.Sprintf("%v -n %v", ns.kubectl(), ns.name)
+		add('1', "get logs", fmt.Sprintf("%v logs %v -c %v", kubectl, cont.pod.name, cont.name))
+		addMutating('2', "exec", fmt.Sprintf("%v exec -it %v -c %v -- %v", kubectl, cont.pod.name, cont.name, cont.shellCommand()))
+		result[len(result)-1].container = cont
+	}
+
+	return result
+}
+
+// shortcutHelp returns footer help text for the item and its shortcuts.
+func shortcutHelp(item Item, shortcuts []shortcut, readOnly bool) []string {
+	help := make([]string, 0)
+	for _, sc := range shortcuts {
+		help = append(help, sc.String())
+	}
+	keys := make([]keyHelp, 0)
+	switch item.Type() {
+	case TypePodGroup, TypeRevision, TypePod, TypeContainer:
+		keys = append(keys, ctrlShortcuts...)
+	}
+	switch item.Type() {
+	case TypePod, TypeContainer:
+		keys = append(keys, keyHelp{"x = exec in app", true})
+	}
+	for _, key := range keys {
+		if !readOnly || !key.mutating {
+			help = append(help, key.text)
+		}
+	}
+	return help
+}
+
+// formatColumns lays out values top to bottom and then left to right into given number of lines.
+func formatColumns(values []string, lineCount int) []string {
+	lines := make([]string, lineCount)
+	for start := 0; start < len(values); start += lineCount {
+		end := start + lineCount
+		if end > len(values) {
+			end = len(values)
+		}
+		width := 0
+		for _, value := range values[start:end] {
+			if len(value) > width {
+				width = len(value)
+			}
+		}
+		for index := range lines {
+			value := ""
+			if start+index < end {
+				value = values[start+index]
+			}
+			lines[index] += value + strings.Repeat(" ", width-len(value)+3)
+		}
+	}
+	return lines
+}
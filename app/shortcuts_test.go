@@ -0,0 +1,119 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPodGroupShortcuts(t *testing.T) {
+	ns := &Namespace{name: "ns", context: "dev"}
+	testTable := []struct {
+		name             string
+		podGroup         PodGroup
+		expectedCommands []string
+	}{
+		{
+			name:     "deployment",
+			podGroup: PodGroup{name: "web", kind: KindDeployment, namespace: ns},
+			expectedCommands: []string{
+				"1 kubectl --context dev -n ns describe deployment/web",
+				"2 delete deployment/web",
+				"3 scale deployment/web",
+				"4 kubectl --context dev -n ns rollout restart deployment/web",
+			},
+		},
+		{
+			name:     "stateful_set",
+			podGroup: PodGroup{name: "db", kind: KindStatefulSet, namespace: ns},
+			expectedCommands: []string{
+				"1 kubectl --context dev -n ns describe statefulset/db",
+				"2 delete statefulset/db",
+				"3 scale statefulset/db",
+				"4 kubectl --context dev -n ns rollout restart statefulset/db",
+			},
+		},
+		{
+			name:     "daemon_set",
+			podGroup: PodGroup{name: "agent", kind: KindDaemonSet, namespace: ns},
+			expectedCommands: []string{
+				"1 kubectl --context dev -n ns describe daemonset/agent",
+				"2 delete daemonset/agent",
+				"4 kubectl --context dev -n ns rollout restart daemonset/agent",
+			},
+		},
+		{
+			name:     "job",
+			podGroup: PodGroup{name: "migration", kind: KindJob, namespace: ns},
+			expectedCommands: []string{
+				"1 kubectl --context dev -n ns describe job/migration",
+				"2 delete job/migration",
+			},
+		},
+		{
+			name:     "cron_job",
+			podGroup: PodGroup{name: "backup", kind: KindCronJob, namespace: ns},
+			expectedCommands: []string{
+				"1 kubectl --context dev -n ns describe cronjob/backup",
+				"2 delete cronjob/backup",
+				"5 kubectl --context dev -n ns create job --from=cronjob/backup backup-manual",
+			},
+		},
+		{
+			name:     "static",
+			podGroup: PodGroup{name: "kube-apiserver-node-1", kind: "Node", namespace: ns, pods: []Pod{{name: "kube-apiserver-node-1"}}},
+			expectedCommands: []string{
+				"1 kubectl --context dev -n ns get pods kube-apiserver-node-1",
+			},
+		},
+		{
+			name:     "ungrouped",
+			podGroup: PodGroup{name: UngroupedPodGroupName, namespace: ns, pods: []Pod{{name: "a"}, {name: "b"}}},
+			expectedCommands: []string{
+				"1 kubectl --context dev -n ns get pods a b",
+			},
+		},
+	}
+
+	for index := range testTable {
+		tc := &testTable[index]
+		t.Run(fmt.Sprintf("%v %v", index, tc.name), func(t *testing.T) {
+			commands := make([]string, 0)
+			for _, sc := range shortcuts(&tc.podGroup) {
+				if sc.mutation != nil {
+					commands = append(commands, fmt.Sprintf("%c %v", sc.key, sc.mutation))
+					continue
+				}
+				commands = append(commands, fmt.Sprintf("%c %v", sc.key, sc.command))
+			}
+			if !reflect.DeepEqual(commands, tc.expectedCommands) {
+				t.Errorf("Invalid commands.\nWant: %q\nGot:  %q", tc.expectedCommands, commands)
+			}
+		})
+	}
+}
+
+func TestFormatColumns(t *testing.T) {
+	lines := formatColumns([]string{"1 = a", "2 = bbb", "3 = c"}, 2)
+	expected := []string{"1 = a     3 = c   ", "2 = bbb           "}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Invalid lines.\nWant: %q\nGot:  %q", expected, lines)
+	}
+}
+
+func TestAssembleCommands(t *testing.T) {
+	ns := &Namespace{name: "ns", context: "it's prod", kubeconfig: "/home/me/my configs/$(id)"}
+	commands := assembleCommands(ns.kubectlArgs(), ns.name, "app", []string{"web-1", "web;2"}, func(pod, container string) []string {
+		return []string{"logs", pod, "-c", container}
+	})
+	expected := [][]string{
+		{"kubectl", "--kubeconfig", "/home/me/my configs/$(id)", "--context", "it's prod", "-n", "ns", "logs", "web-1", "-c", "app"},
+		{"kubectl", "--kubeconfig", "/home/me/my configs/$(id)", "--context", "it's prod", "-n", "ns", "logs", "web;2", "-c", "app"},
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("Invalid commands.\nWant: %q\nGot:  %q", expected, commands)
+	}
+	if kubectl := ns.kubectl(); kubectl != `kubectl --kubeconfig '/home/me/my configs/$(id)' --context 'it'\''s prod'` {
+		t.Errorf("Invalid kubectl prefix: %v", kubectl)
+	}
+}
@@ -2,8 +2,11 @@ package app
 
 import (
 	"fmt"
+	"github.com/JLevconoks/k8ConsoleViewer/terminal"
 	"github.com/gdamore/tcell"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/kubernetes/pkg/util/node"
 	"sort"
@@ -16,6 +19,7 @@ type Type int
 const (
 	TypeNamespace Type = iota + 1
 	TypePodGroup
+	TypeRevision
 	TypePod
 	TypeContainer
 	TypeNamespaceError
@@ -31,6 +35,7 @@ type Item interface {
 type Namespace struct {
 	name        string
 	context     string
+	kubeconfig  string
 	deployments []*PodGroup
 	nsError     NamespaceError
 	nsMessage   NamespaceMessage
@@ -53,11 +58,67 @@ func (n *Namespace) DisplayName() string {
 	return fmt.Sprintf("%v / %v", n.name, n.context)
 }
 
+func (n *Namespace) cluster() clusterKey {
+	return clusterKey{kubeconfig: n.kubeconfig, context: n.context}
+}
+
+// kubectl returns kubectl command prefix targeting namespace's cluster, without the namespace flag.
+func (n *Namespace) kubectl() string {
+	return terminal.ShellJoin(n.kubectlArgs())
+}
+
+// kubectlArgs returns kubectl argv prefix targeting namespace's cluster, without the namespace flag.
+func (n *Namespace) kubectlArgs() []string {
+	if n.kubeconfig != "" {
+		return []string{"kubectl", "--kubeconfig", n.kubeconfig, "--context", n.context}
+	}
+	return []string{"kubectl", "--context", n.context}
+}
+
+// isHealthy reports whether namespace could be listed and all of its pod groups are healthy.
+func (n *Namespace) isHealthy() bool {
+	if n.nsError.error != nil {
+		return false
+	}
+	for _, pg := range n.deployments {
+		if !pg.isHealthy() {
+			return false
+		}
+	}
+	return true
+}
+
+// linkParents points children to their parents, it has to be called once namespace is at its final place in memory,
+// as namespaces and pods are created by value.
+func (n *Namespace) linkParents() {
+	n.nsError.namespace = n
+	n.nsMessage.namespace = n
+	for _, pg := range n.deployments {
+		pg.namespace = n
+		for pIndex := range pg.pods {
+			for cIndex := range pg.pods[pIndex].containers {
+				pg.pods[pIndex].containers[cIndex].pod = &pg.pods[pIndex]
+			}
+		}
+	}
+}
+
 type PodGroup struct {
-	name       string
+	name string
+	// uid of the owning resource, empty when it is unknown.
+	uid types.UID
+	// kind of the resource owning the pods, it is empty when it is unknown.
+	kind       string
 	pods       []Pod
 	isExpanded bool
 	namespace  *Namespace
+	// job and cronJob are set for groups owned by a Job or a CronJob.
+	job     *jobStatus
+	cronJob *cronJobStatus
+	// replicas is set for groups owned by a Deployment, StatefulSet or DaemonSet.
+	replicas *replicaStatus
+	// revisions are set only for Deployments which have pods of more than one ReplicaSet, i.e. during a rollout.
+	revisions []*Revision
 }
 
 func (pg *PodGroup) Type() Type {
@@ -72,17 +133,57 @@ func (pg *PodGroup) IsExpanded() bool {
 	return pg.isExpanded
 }
 
+// kindName returns lower case kind as it is used in kubectl.
+func (pg *PodGroup) kindName() string {
+	return strings.ToLower(pg.kind)
+}
+
+// resource returns '<kind>/<name>' reference to the owning resource.
+func (pg *PodGroup) resource() string {
+	return pg.kindName() + "/" + pg.name
+}
+
+// countReadyPods counts pods with all containers ready, completed pods are counted as ready as well.
 func (pg *PodGroup) countReadyPods() (ready int) {
 	ready = 0
 
 	for pIndex := range pg.pods {
-		if pg.pods[pIndex].ready == pg.pods[pIndex].total {
+		if pg.pods[pIndex].ready == pg.pods[pIndex].total || pg.pods[pIndex].isCompleted() {
 			ready++
 		}
 	}
 	return ready
 }
 
+// isHealthy reports whether all pods are ready, for jobs it depends on job not being failed instead, as failed pods
+// are expected to be retried. Controllers must also have all desired replicas ready.
+func (pg *PodGroup) isHealthy() bool {
+	switch {
+	case pg.job != nil:
+		return !pg.job.isFailed
+	case pg.cronJob != nil:
+		return pg.cronJob.lastJob == nil || !pg.cronJob.lastJob.isFailed
+	case pg.replicas != nil && !pg.replicas.isHealthy():
+		return false
+	}
+	return pg.countReadyPods() == len(pg.pods)
+}
+
+// statusInfo returns additional status shown next to the pod count.
+func (pg *PodGroup) statusInfo() string {
+	switch {
+	case pg.job != nil:
+		return pg.job.String()
+	case pg.cronJob != nil:
+		return pg.cronJob.String()
+	case pg.replicas != nil && len(pg.revisions) > 0:
+		return fmt.Sprintf("%d/%d updated, %v", pg.replicas.updated, pg.replicas.desired, pg.replicas)
+	case pg.replicas != nil:
+		return pg.replicas.String()
+	}
+	return ""
+}
+
 func (pg *PodGroup) podNames() []string {
 	names := make([]string, 0)
 	for index := range pg.pods {
@@ -93,15 +194,21 @@ func (pg *PodGroup) podNames() []string {
 
 type Pod struct {
 	name         string
+	uid          types.UID
 	ready        int
 	total        int
+	phase        v1.PodPhase
 	status       string
 	restarts     int
 	age          string
 	creationTime time.Time
+	// templateHash is the pod-template-hash label, which identifies pod's ReplicaSet revision.
+	templateHash string
 	containers   []Container
 	isExpanded   bool
 	podGroup     *PodGroup
+	// revision is set when pods of the group are split by revisions.
+	revision *Revision
 }
 
 func (p *Pod) Type() Type {
@@ -120,6 +227,19 @@ func (p *Pod) ReadyString() string {
 	return fmt.Sprintf("%d/%d", p.ready, p.total)
 }
 
+// isCompleted reports whether all containers of the pod terminated successfully, which is normal for Job pods.
+func (p *Pod) isCompleted() bool {
+	return p.phase == v1.PodSucceeded
+}
+
+func (p *Pod) isRunning() bool {
+	return p.status == "Running"
+}
+
+func (p *Pod) isHealthy() bool {
+	return p.isCompleted() || (p.isRunning() && p.ready >= p.total)
+}
+
 func (p *Pod) containerNames() []string {
 	names := make([]string, 0)
 
@@ -130,6 +250,14 @@ func (p *Pod) containerNames() []string {
 	return names
 }
 
+func (p *Pod) containerVersions() []string {
+	versions := make([]string, 0)
+	for index := range p.containers {
+		versions = append(versions, p.containers[index].version)
+	}
+	return versions
+}
+
 type Container struct {
 	name       string
 	image      string
@@ -138,6 +266,9 @@ type Container struct {
 	ready      bool
 	isExpanded bool
 	pod        *Pod
+	// shell is found by probing the container image, it is empty when the image has no shell.
+	shell       []string
+	shellProbed bool
 }
 
 func (c Container) Type() Type {
@@ -225,44 +356,56 @@ func (i *StringItem) UpdateS(s tcell.Screen, newValue string, style tcell.Style)
 
 func toNamespace(plr *PodListResult) Namespace {
 	ns := Namespace{
-		name:    plr.namespace,
-		context: plr.context,
+		name:       plr.namespace,
+		context:    plr.context,
+		kubeconfig: plr.kubeconfig,
 	}
 	ns.nsError = NamespaceError{
 		error:     plr.error,
 		namespace: &ns,
 	}
 
-	if len(plr.Items) == 0 {
+	ns.deployments = toPodGroup(plr, &ns)
+	messages := make([]string, 0)
+	if len(ns.deployments) == 0 {
+		messages = append(messages, "No resources found.")
+	}
+	if len(plr.ownerErrors) > 0 {
+		messages = append(messages, ownerErrorsMessage(plr.ownerErrors))
+	}
+	if len(messages) > 0 {
 		ns.nsMessage = NamespaceMessage{
-			message:   "No resources found.",
+			message:   strings.Join(messages, " "),
 			namespace: &ns,
 		}
 	}
-
-	ns.deployments = toPodGroup(plr.Items, &ns)
 	return ns
 }
 
-func toPodGroup(pods []v1.Pod, parent *Namespace) []*PodGroup {
-	podGroup := make(map[string]*PodGroup)
+// ownerErrorsMessage explains why pods may be grouped by labels or shown without replica counts, errors usually come
+// from missing permissions, which are the same for all owners, so only the first one is shown.
+func ownerErrorsMessage(errs []error) string {
+	message := fmt.Sprintf("Owners not listed, grouping may be incomplete: %v", errs[0])
+	if len(errs) > 1 {
+		message += fmt.Sprintf(" (and %d more)", len(errs)-1)
+	}
+	return message
+}
 
-	for _, pod := range pods {
-		podGroupName := "_"
+func toPodGroup(plr *PodListResult, parent *Namespace) []*PodGroup {
+	podGroup := make(map[string]*PodGroup)
+	grouper := newPodGrouper(plr)
 
-		switch {
-		case pod.Labels["deployment"] != "":
-			podGroupName = pod.Labels["deployment"]
-		case pod.Labels["statefulSet"] != "":
-			podGroupName = pod.Labels["statefulSet"]
-		case pod.Labels["job-name"] != "":
-			podGroupName = pod.Labels["job-name"]
-		}
+	for _, pod := range plr.Items {
+		podGroupName, kind := grouper.groupOf(&pod)
+		// Different kinds of resources can have the same name.
+		key := kind + "/" + podGroupName
 
-		d, ok := podGroup[podGroupName]
+		d, ok := podGroup[key]
 		if !ok {
 			d = &PodGroup{
 				name:       podGroupName,
+				kind:       kind,
 				pods:       make([]Pod, 0),
 				isExpanded: false,
 				namespace:  parent,
@@ -270,7 +413,7 @@ func toPodGroup(pods []v1.Pod, parent *Namespace) []*PodGroup {
 		}
 
 		d.pods = append(d.pods, toPod(pod, d))
-		podGroup[podGroupName] = d
+		podGroup[key] = d
 	}
 
 	podGroups := make([]*PodGroup, len(podGroup))
@@ -280,16 +423,29 @@ func toPodGroup(pods []v1.Pod, parent *Namespace) []*PodGroup {
 		podGroups[index] = d
 		index++
 	}
+	podGroups = applyReplicaStatuses(podGroups, plr, grouper, parent)
 
 	sort.Slice(podGroups, func(i, j int) bool {
-		return podGroups[i].name < podGroups[j].name
+		if podGroups[i].name != podGroups[j].name {
+			return podGroups[i].name < podGroups[j].name
+		}
+		return podGroups[i].kind < podGroups[j].kind
 	})
+	applyJobStatuses(podGroups, plr)
+	applyRevisions(podGroups, plr)
+	applyOwnerUIDs(podGroups, plr)
 
 	return podGroups
 }
 
 func toPod(p v1.Pod, parent *PodGroup) Pod {
-	pod := Pod{name: p.Name, podGroup: parent}
+	pod := Pod{
+		name:         p.Name,
+		uid:          p.UID,
+		phase:        p.Status.Phase,
+		templateHash: p.Labels[appsv1.DefaultDeploymentUniqueLabelKey],
+		podGroup:     parent,
+	}
 
 	status, ready, total, restarts, creationTime := podStats(&p)
 
@@ -317,22 +473,25 @@ func toContainer(cs v1.ContainerStatus, parent *Pod) Container {
 		msg = cs.State.Terminated.Message
 	}
 
-	versionPosition := strings.LastIndex(cs.Image, ":")
-	var version string
-	if versionPosition > 0 {
-		version = cs.Image[versionPosition+1:]
-	}
-
 	return Container{
 		name:    cs.Name,
 		image:   cs.Image,
-		version: version,
+		version: imageVersion(cs.Image),
 		message: msg,
 		ready:   cs.Ready,
 		pod:     parent,
 	}
 }
 
+// imageVersion returns image tag, or an empty string when image has no tag.
+func imageVersion(image string) string {
+	versionPosition := strings.LastIndex(image, ":")
+	if versionPosition > 0 {
+		return image[versionPosition+1:]
+	}
+	return ""
+}
+
 // This logic is pretty much a copy of https://github.com/kubernetes/kubernetes/tree/master/pkg/printers/internalversion/printers.go
 // printPod() function
 func podStats(pod *v1.Pod) (status string, ready int, total int, restarts int, creationTime time.Time) {
@@ -345,13 +504,7 @@ func podStats(pod *v1.Pod) (status string, ready int, total int, restarts int, c
 		reason = pod.Status.Reason
 	}
 
-	// TODO: Check Job pod statuses.
-	//switch pod.Status.Phase {
-	//case v1.PodSucceeded:
-	//	row.Conditions = podSuccessConditions
-	//case api.PodFailed:
-	//	row.Conditions = podFailedConditions
-	//}
+	// Job pod statuses (Succeeded/Failed phases) are handled in Pod.isCompleted() and PodGroup.isHealthy().
 
 	initializing := false
 	for i := range pod.Status.InitContainerStatuses {
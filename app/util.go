@@ -0,0 +1,20 @@
+package app
+
+// clampInt limits value to the range from low to high, low wins when the range is empty.
+func clampInt(value, low, high int) int {
+	return maxInt(low, minInt(value, high))
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,459 @@
+package app
+
+import (
+	"github.com/gdamore/tcell"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// vtScreen is a minimal VT100/xterm emulator, it keeps a grid of cells which is updated by output of a remote
+// terminal. Only sequences used by common shells and full screen tools are supported, others are ignored.
+type vtScreen struct {
+	sync.Mutex
+
+	width, height    int
+	cells            [][]vtCell
+	cursorX, cursorY int
+	savedX, savedY   int
+	style            tcell.Style
+	// scrollTop and scrollBottom are the first and the last row of the scrolling region.
+	scrollTop, scrollBottom int
+	// wrapNext is set after a rune was written to the last column, next rune goes to the next line.
+	wrapNext bool
+
+	state   vtState
+	params  strings.Builder
+	pending []byte
+}
+
+type vtCell struct {
+	r     rune
+	style tcell.Style
+}
+
+type vtState int
+
+const (
+	vtGround vtState = iota
+	vtEscape
+	vtCharset
+	vtCSI
+	vtOSC
+	vtOSCEscape
+)
+
+const vtTabWidth = 8
+
+func newVTScreen(width, height int) *vtScreen {
+	vt := &vtScreen{style: tcell.StyleDefault}
+	vt.resize(width, height)
+	return vt
+}
+
+// resize keeps content at the top left corner, cursor is moved inside the new size.
+func (vt *vtScreen) resize(width, height int) {
+	vt.Lock()
+	defer vt.Unlock()
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	cells := make([][]vtCell, height)
+	for y := range cells {
+		cells[y] = make([]vtCell, width)
+		for x := range cells[y] {
+			cells[y][x] = vtCell{r: ' ', style: tcell.StyleDefault}
+			if y < len(vt.cells) && x < len(vt.cells[y]) {
+				cells[y][x] = vt.cells[y][x]
+			}
+		}
+	}
+	vt.cells = cells
+	vt.width, vt.height = width, height
+	vt.scrollTop, vt.scrollBottom = 0, height-1
+	vt.wrapNext = false
+	vt.moveCursor(vt.cursorX, vt.cursorY)
+}
+
+// Write parses terminal output, it never fails so that remote streams are not interrupted by unknown sequences.
+func (vt *vtScreen) Write(p []byte) (int, error) {
+	vt.Lock()
+	defer vt.Unlock()
+	data := append(vt.pending, p...)
+	vt.pending = nil
+	for len(data) > 0 {
+		b := data[0]
+		if vt.state != vtGround || b < utf8.RuneSelf {
+			vt.parseByte(b)
+			data = data[1:]
+			continue
+		}
+		if !utf8.FullRune(data) {
+			// Rest of the rune comes with the next write.
+			vt.pending = append([]byte{}, data...)
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		vt.put(r)
+		data = data[size:]
+	}
+	return len(p), nil
+}
+
+func (vt *vtScreen) parseByte(b byte) {
+	switch vt.state {
+	case vtGround:
+		vt.control(b)
+	case vtEscape:
+		vt.escape(b)
+	case vtCharset:
+		vt.state = vtGround
+	case vtCSI:
+		switch {
+		case b >= 0x40 && b <= 0x7e:
+			vt.state = vtGround
+			vt.csi(b, vt.params.String())
+		case b == 0x1b:
+			vt.state = vtEscape
+		case b < 0x20:
+			vt.control(b)
+		default:
+			vt.params.WriteByte(b)
+		}
+	case vtOSC:
+		// Window titles and similar are ignored, OSC ends with BEL or ESC \.
+		switch b {
+		case 0x07:
+			vt.state = vtGround
+		case 0x1b:
+			vt.state = vtOSCEscape
+		}
+	case vtOSCEscape:
+		vt.state = vtGround
+	}
+}
+
+func (vt *vtScreen) control(b byte) {
+	switch b {
+	case 0x1b:
+		vt.state = vtEscape
+	case '\r':
+		vt.moveCursor(0, vt.cursorY)
+	case '\n', '\v', '\f':
+		vt.lineFeed()
+	case '\b':
+		vt.moveCursor(vt.cursorX-1, vt.cursorY)
+	case '\t':
+		vt.moveCursor((vt.cursorX/vtTabWidth+1)*vtTabWidth, vt.cursorY)
+	default:
+		if b >= 0x20 && b < 0x7f {
+			vt.put(rune(b))
+		}
+	}
+}
+
+func (vt *vtScreen) escape(b byte) {
+	vt.state = vtGround
+	switch b {
+	case '[':
+		vt.params.Reset()
+		vt.state = vtCSI
+	case ']':
+		vt.state = vtOSC
+	case '(', ')', '*', '+':
+		vt.state = vtCharset
+	case '7':
+		vt.savedX, vt.savedY = vt.cursorX, vt.cursorY
+	case '8':
+		vt.moveCursor(vt.savedX, vt.savedY)
+	case 'D':
+		vt.lineFeed()
+	case 'E':
+		vt.lineFeed()
+		vt.moveCursor(0, vt.cursorY)
+	case 'M':
+		if vt.cursorY == vt.scrollTop {
+			vt.scrollDown(1)
+		} else {
+			vt.moveCursor(vt.cursorX, vt.cursorY-1)
+		}
+	case 'c':
+		vt.style = tcell.StyleDefault
+		vt.scrollTop, vt.scrollBottom = 0, vt.height-1
+		vt.eraseRect(0, 0, vt.width, vt.height)
+		vt.moveCursor(0, 0)
+	}
+}
+
+func (vt *vtScreen) csi(final byte, params string) {
+	private := strings.HasPrefix(params, "?")
+	args := vtParams(strings.TrimLeft(params, "?>="))
+	arg := func(index, defaultValue int) int {
+		if index < len(args) && args[index] > 0 {
+			return args[index]
+		}
+		return defaultValue
+	}
+
+	switch final {
+	case 'A':
+		vt.moveCursor(vt.cursorX, vt.cursorY-arg(0, 1))
+	case 'B', 'e':
+		vt.moveCursor(vt.cursorX, vt.cursorY+arg(0, 1))
+	case 'C', 'a':
+		vt.moveCursor(vt.cursorX+arg(0, 1), vt.cursorY)
+	case 'D':
+		vt.moveCursor(vt.cursorX-arg(0, 1), vt.cursorY)
+	case 'E':
+		vt.moveCursor(0, vt.cursorY+arg(0, 1))
+	case 'F':
+		vt.moveCursor(0, vt.cursorY-arg(0, 1))
+	case 'G', '`':
+		vt.moveCursor(arg(0, 1)-1, vt.cursorY)
+	case 'd':
+		vt.moveCursor(vt.cursorX, arg(0, 1)-1)
+	case 'H', 'f':
+		vt.moveCursor(arg(1, 1)-1, arg(0, 1)-1)
+	case 'J':
+		switch arg(0, 0) {
+		case 0:
+			vt.eraseRect(vt.cursorX, vt.cursorY, vt.width, vt.cursorY+1)
+			vt.eraseRect(0, vt.cursorY+1, vt.width, vt.height)
+		case 1:
+			vt.eraseRect(0, 0, vt.width, vt.cursorY)
+			vt.eraseRect(0, vt.cursorY, vt.cursorX+1, vt.cursorY+1)
+		default:
+			vt.eraseRect(0, 0, vt.width, vt.height)
+		}
+	case 'K':
+		switch arg(0, 0) {
+		case 0:
+			vt.eraseRect(vt.cursorX, vt.cursorY, vt.width, vt.cursorY+1)
+		case 1:
+			vt.eraseRect(0, vt.cursorY, vt.cursorX+1, vt.cursorY+1)
+		default:
+			vt.eraseRect(0, vt.cursorY, vt.width, vt.cursorY+1)
+		}
+	case 'X':
+		vt.eraseRect(vt.cursorX, vt.cursorY, vt.cursorX+arg(0, 1), vt.cursorY+1)
+	case '@':
+		vt.shiftLine(arg(0, 1))
+	case 'P':
+		vt.shiftLine(-arg(0, 1))
+	case 'L':
+		if vt.cursorY >= vt.scrollTop && vt.cursorY <= vt.scrollBottom {
+			vt.scrollRegion(vt.cursorY, vt.scrollBottom, -arg(0, 1))
+		}
+	case 'M':
+		if vt.cursorY >= vt.scrollTop && vt.cursorY <= vt.scrollBottom {
+			vt.scrollRegion(vt.cursorY, vt.scrollBottom, arg(0, 1))
+		}
+	case 'S':
+		vt.scrollUp(arg(0, 1))
+	case 'T':
+		vt.scrollDown(arg(0, 1))
+	case 'r':
+		top, bottom := arg(0, 1)-1, arg(1, vt.height)-1
+		if top < bottom && bottom < vt.height {
+			vt.scrollTop, vt.scrollBottom = top, bottom
+			vt.moveCursor(0, 0)
+		}
+	case 's':
+		vt.savedX, vt.savedY = vt.cursorX, vt.cursorY
+	case 'u':
+		vt.moveCursor(vt.savedX, vt.savedY)
+	case 'm':
+		if !private {
+			vt.style = sgrStyle(vt.style, args)
+		}
+	case 'h', 'l':
+		// Alternate screen buffer is not kept, switching just clears the screen.
+		if private && (arg(0, 0) == 1049 || arg(0, 0) == 47) {
+			vt.eraseRect(0, 0, vt.width, vt.height)
+		}
+	}
+}
+
+// vtParams parses semicolon separated numbers, missing numbers are 0.
+func vtParams(params string) []int {
+	if params == "" {
+		return nil
+	}
+	parts := strings.Split(params, ";")
+	values := make([]int, len(parts))
+	for index, part := range parts {
+		values[index], _ = strconv.Atoi(part)
+	}
+	return values
+}
+
+// sgrStyle applies Select Graphic Rendition parameters to style.
+func sgrStyle(style tcell.Style, args []int) tcell.Style {
+	if len(args) == 0 {
+		return tcell.StyleDefault
+	}
+	for index := 0; index < len(args); index++ {
+		arg := args[index]
+		switch {
+		case arg == 0:
+			style = tcell.StyleDefault
+		case arg == 1:
+			style = style.Bold(true)
+		case arg == 2:
+			style = style.Dim(true)
+		case arg == 4:
+			style = style.Underline(true)
+		case arg == 5:
+			style = style.Blink(true)
+		case arg == 7:
+			style = style.Reverse(true)
+		case arg == 22:
+			style = style.Bold(false).Dim(false)
+		case arg == 24:
+			style = style.Underline(false)
+		case arg == 25:
+			style = style.Blink(false)
+		case arg == 27:
+			style = style.Reverse(false)
+		case arg >= 30 && arg <= 37:
+			style = style.Foreground(tcell.Color(arg - 30))
+		case arg == 39:
+			style = style.Foreground(tcell.ColorDefault)
+		case arg >= 40 && arg <= 47:
+			style = style.Background(tcell.Color(arg - 40))
+		case arg == 49:
+			style = style.Background(tcell.ColorDefault)
+		case arg >= 90 && arg <= 97:
+			style = style.Foreground(tcell.Color(arg - 90 + 8))
+		case arg >= 100 && arg <= 107:
+			style = style.Background(tcell.Color(arg - 100 + 8))
+		case arg == 38 || arg == 48:
+			colour, used := extendedColour(args[index+1:])
+			index += used
+			if arg == 38 {
+				style = style.Foreground(colour)
+			} else {
+				style = style.Background(colour)
+			}
+		}
+	}
+	return style
+}
+
+// extendedColour parses 5;n and 2;r;g;b colours which follow 38 or 48, it returns number of used parameters.
+func extendedColour(args []int) (tcell.Color, int) {
+	switch {
+	case len(args) >= 2 && args[0] == 5:
+		return tcell.Color(args[1]), 2
+	case len(args) >= 4 && args[0] == 2:
+		return tcell.NewRGBColor(int32(args[1]), int32(args[2]), int32(args[3])), 4
+	}
+	return tcell.ColorDefault, len(args)
+}
+
+func (vt *vtScreen) put(r rune) {
+	if vt.wrapNext {
+		vt.wrapNext = false
+		vt.lineFeed()
+		vt.cursorX = 0
+	}
+	vt.cells[vt.cursorY][vt.cursorX] = vtCell{r: r, style: vt.style}
+	if vt.cursorX == vt.width-1 {
+		vt.wrapNext = true
+	} else {
+		vt.cursorX++
+	}
+}
+
+func (vt *vtScreen) moveCursor(x, y int) {
+	vt.wrapNext = false
+	vt.cursorX = clampInt(x, 0, vt.width-1)
+	vt.cursorY = clampInt(y, 0, vt.height-1)
+}
+
+func (vt *vtScreen) lineFeed() {
+	if vt.cursorY == vt.scrollBottom {
+		vt.scrollUp(1)
+		return
+	}
+	vt.moveCursor(vt.cursorX, vt.cursorY+1)
+}
+
+func (vt *vtScreen) scrollUp(n int) {
+	vt.scrollRegion(vt.scrollTop, vt.scrollBottom, n)
+}
+
+func (vt *vtScreen) scrollDown(n int) {
+	vt.scrollRegion(vt.scrollTop, vt.scrollBottom, -n)
+}
+
+// scrollRegion moves rows from top to bottom up by n, or down when n is negative, and clears rows which are revealed.
+func (vt *vtScreen) scrollRegion(top, bottom, n int) {
+	count := bottom + 1 - top
+	n = clampInt(n, -count, count)
+	// Rows are rotated, so that rows which are cleared are reused.
+	rows := make([][]vtCell, 0, count)
+	shift := (n + count) % count
+	rows = append(rows, vt.cells[top+shift:bottom+1]...)
+	rows = append(rows, vt.cells[top:top+shift]...)
+	copy(vt.cells[top:], rows)
+	if n > 0 {
+		vt.eraseRect(0, bottom+1-n, vt.width, bottom+1)
+	} else if n < 0 {
+		vt.eraseRect(0, top, vt.width, top-n)
+	}
+}
+
+// shiftLine moves cells from the cursor to the end of the line right by n, or left when n is negative.
+func (vt *vtScreen) shiftLine(n int) {
+	line := vt.cells[vt.cursorY]
+	if n > 0 {
+		copy(line[minInt(vt.cursorX+n, vt.width):], line[vt.cursorX:])
+		vt.eraseRect(vt.cursorX, vt.cursorY, vt.cursorX+n, vt.cursorY+1)
+	} else if n < 0 {
+		copy(line[vt.cursorX:], line[minInt(vt.cursorX-n, vt.width):])
+		vt.eraseRect(vt.width+n, vt.cursorY, vt.width, vt.cursorY+1)
+	}
+}
+
+// eraseRect clears cells from x1, y1 up to, but not including, x2, y2 using current background.
+func (vt *vtScreen) eraseRect(x1, y1, x2, y2 int) {
+	_, bg, _ := vt.style.Decompose()
+	blank := vtCell{r: ' ', style: tcell.StyleDefault.Background(bg)}
+	for y := maxInt(y1, 0); y < minInt(y2, vt.height); y++ {
+		for x := maxInt(x1, 0); x < minInt(x2, vt.width); x++ {
+			vt.cells[y][x] = blank
+		}
+	}
+}
+
+// draw copies cells to the screen with top left corner at x, y and moves the screen cursor.
+func (vt *vtScreen) draw(s tcell.Screen, x, y int) {
+	vt.Lock()
+	defer vt.Unlock()
+	for row := range vt.cells {
+		for column, cell := range vt.cells[row] {
+			s.SetContent(x+column, y+row, cell.r, nil, cell.style)
+		}
+	}
+	s.ShowCursor(x+vt.cursorX, y+vt.cursorY)
+}
+
+// text returns content of the screen without styles and trailing spaces.
+func (vt *vtScreen) text() string {
+	vt.Lock()
+	defer vt.Unlock()
+	lines := make([]string, len(vt.cells))
+	for y, row := range vt.cells {
+		var builder strings.Builder
+		for _, cell := range row {
+			builder.WriteRune(cell.r)
+		}
+		lines[y] = strings.TrimRight(builder.String(), " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"testing"
+)
+
+func TestVTScreenWrite(t *testing.T) {
+	testTable := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{"text", "hello\r\nworld", "hello\nworld"},
+		{"wrap", "abcdefghij", "abcdefgh\nij"},
+		{"scroll", "1\r\n2\r\n3\r\n4", "2\n3\n4"},
+		{"backspace", "ab\bc", "ac"},
+		{"erase_line", "hello\r\x1b[Kbye", "bye"},
+		{"cursor_position", "\x1b[2;3Hx", "\n  x"},
+		{"erase_display", "abc\r\ndef\x1b[2J\x1b[Hz", "z"},
+		{"delete_chars", "abcdef\x1b[4D\x1b[2P", "abef"},
+		{"insert_chars", "abcd\x1b[3D\x1b[2@", "a  bcd"},
+		{"colours_ignored", "\x1b[1;31mred\x1b[0m", "red"},
+		{"title_ignored", "\x1b]0;user@pod: ~\x07$ ", "$"},
+		{"utf8", "zaļš", "zaļš"},
+		{"unknown_sequence", "\x1b[?2004ha", "a"},
+	}
+
+	for index, tc := range testTable {
+		t.Run(fmt.Sprintf("%v %v", index, tc.name), func(t *testing.T) {
+			vt := newVTScreen(8, 3)
+			_, _ = vt.Write([]byte(tc.output))
+			if text := vt.text(); text != tc.expected {
+				t.Errorf("Invalid screen.\nWant: %q\nGot:  %q", tc.expected, text)
+			}
+		})
+	}
+}
+
+func TestVTScreenSplitWrites(t *testing.T) {
+	vt := newVTScreen(10, 2)
+	output := []byte("\x1b[31mļ\x1b[0m")
+	for _, b := range output {
+		_, _ = vt.Write([]byte{b})
+	}
+	if text := vt.text(); text != "ļ" {
+		t.Errorf("Invalid screen. Want: %q, Got: %q", "ļ", text)
+	}
+	if fg, _, _ := vt.cells[0][0].style.Decompose(); fg != tcell.ColorMaroon {
+		t.Errorf("Invalid foreground. Want: %v, Got: %v", tcell.ColorMaroon, fg)
+	}
+}
+
+func TestVTScreenResize(t *testing.T) {
+	vt := newVTScreen(10, 5)
+	_, _ = vt.Write([]byte("\x1b[5;10H"))
+	vt.resize(4, 2)
+	if vt.cursorX != 3 || vt.cursorY != 1 {
+		t.Errorf("Cursor should stay inside the screen, Got: %v, %v", vt.cursorX, vt.cursorY)
+	}
+}
+
+func TestSGRStyle(t *testing.T) {
+	style := sgrStyle(tcell.StyleDefault, []int{38, 5, 208, 48, 2, 1, 2, 3, 1})
+	fg, bg, attrs := style.Decompose()
+	if fg != tcell.Color(208) || bg != tcell.NewRGBColor(1, 2, 3) || attrs&tcell.AttrBold == 0 {
+		t.Errorf("Invalid style, Got: %v, %v, %v", fg, bg, attrs)
+	}
+}
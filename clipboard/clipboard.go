@@ -1,25 +1,198 @@
 package clipboard
 
 import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
 )
 
-func ToClipboard(s string) error {
-	copyCmd := exec.Command("pbcopy")
-	in, err := copyCmd.StdinPipe()
-	if err != nil {
-		return err
+const (
+	BackendAuto   = "auto"
+	BackendWlCopy = "wl-copy"
+	BackendXClip  = "xclip"
+	BackendXSel   = "xsel"
+	BackendPbCopy = "pbcopy"
+	BackendOSC52  = "osc52"
+)
+
+// Backend copies a value to a clipboard.
+type Backend interface {
+	Name() string
+	Copy(value string) error
+}
+
+// Clipboard copies values with the first of its backends which succeeds.
+type Clipboard struct {
+	backends []Backend
+	terminal *terminalLock
+}
+
+// terminalLock is held while an escape sequence is written to the terminal, so that it isn't written in the middle of
+// a screen update. It is set once the screen is created and does nothing before that.
+type terminalLock struct {
+	locker sync.Locker
+}
+
+func (tl *terminalLock) Lock() {
+	if tl.locker != nil {
+		tl.locker.Lock()
+	}
+}
+
+func (tl *terminalLock) Unlock() {
+	if tl.locker != nil {
+		tl.locker.Unlock()
+	}
+}
+
+// runner runs a program with input as its standard input, it is replaced in tests.
+type runner func(input string, name string, args ...string) error
+
+func runCommand(input string, name string, args ...string) error {
+	// Output is not captured, wl-copy and xclip keep running in the background to serve the selection and would hold
+	// the pipes open.
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%v: %v", name, err)
+	}
+	return nil
+}
+
+func openTTY() (io.WriteCloser, error) {
+	return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+}
+
+// New returns clipboard with backend by name, empty name or auto tries all backends available in the environment.
+func New(name string) (*Clipboard, error) {
+	terminal := &terminalLock{}
+	switch name {
+	case "", BackendAuto:
+		return detectBackends(os.Getenv, runtime.GOOS, exec.LookPath, terminal), nil
+	case BackendWlCopy, BackendXClip, BackendXSel, BackendPbCopy:
+		return &Clipboard{backends: []Backend{newCommandBackend(name)}, terminal: terminal}, nil
+	case BackendOSC52:
+		return &Clipboard{backends: []Backend{newOSC52Backend(os.Getenv, terminal)}, terminal: terminal}, nil
+	}
+	return nil, fmt.Errorf("unknown clipboard '%v', supported clipboards: %v", name,
+		strings.Join([]string{BackendAuto, BackendWlCopy, BackendXClip, BackendXSel, BackendPbCopy, BackendOSC52}, ", "))
+}
+
+// detectBackends returns programs found in PATH, in the order of wl-copy, xclip and xsel, with OSC 52 as the last
+// resort, as the terminal doesn't tell whether it has set the clipboard and so OSC 52 never fails.
+// Over SSH the X11 and Wayland programs work only with a forwarded display, which is on the local machine, but pbcopy
+// would copy to the clipboard of the remote Mac, so OSC 52 goes before it.
+func detectBackends(getenv func(string) string, goos string, lookPath func(string) (string, error), terminal *terminalLock) *Clipboard {
+	remote := getenv("SSH_TTY") != "" || getenv("SSH_CONNECTION") != ""
+	commands := make([]Backend, 0)
+	names := []string{BackendWlCopy, BackendXClip, BackendXSel}
+	if goos == "darwin" {
+		names = []string{BackendPbCopy}
+	}
+	for _, name := range names {
+		if _, err := lookPath(name); err == nil {
+			commands = append(commands, newCommandBackend(name))
+		}
+	}
+	osc52 := newOSC52Backend(getenv, terminal)
+	if remote && goos == "darwin" {
+		return &Clipboard{backends: append([]Backend{osc52}, commands...), terminal: terminal}
 	}
-	if err := copyCmd.Start(); err != nil {
-		return err
+	return &Clipboard{backends: append(commands, osc52), terminal: terminal}
+}
+
+// SetTerminalLock sets the lock the screen holds while it writes to the terminal, OSC 52 sequences are written with
+// it held as well.
+func (c *Clipboard) SetTerminalLock(locker sync.Locker) {
+	c.terminal.locker = locker
+}
+
+// Copy copies value and returns name of the backend which was used.
+func (c *Clipboard) Copy(value string) (string, error) {
+	errs := make([]string, 0)
+	for _, backend := range c.backends {
+		err := backend.Copy(value)
+		if err == nil {
+			return backend.Name(), nil
+		}
+		errs = append(errs, err.Error())
 	}
-	if _, err := in.Write([]byte(s)); err != nil {
-		return err
+	if len(errs) == 0 {
+		return "", fmt.Errorf("no clipboard available")
 	}
-	if err := in.Close(); err != nil {
-		return err
+	return "", fmt.Errorf("%v", strings.Join(errs, "; "))
+}
+
+// commandBackend pipes value to a program.
+type commandBackend struct {
+	name string
+	args []string
+	run  runner
+}
+
+func newCommandBackend(name string) commandBackend {
+	args := make([]string, 0)
+	switch name {
+	case BackendXClip:
+		args = []string{"-selection", "clipboard"}
+	case BackendXSel:
+		args = []string{"--clipboard", "--input"}
 	}
+	return commandBackend{name: name, args: args, run: runCommand}
+}
+
+func (b commandBackend) Name() string {
+	return b.name
+}
+
+func (b commandBackend) Copy(value string) error {
+	return b.run(value, b.name, b.args...)
+}
 
-	return copyCmd.Wait()
+// osc52Backend asks the terminal to set its clipboard with OSC 52 escape sequence, which also works over SSH. tcell
+// can't send raw sequences, so it is written to the controlling terminal directly, with the terminal locked.
+type osc52Backend struct {
+	tmux     bool
+	open     func() (io.WriteCloser, error)
+	terminal sync.Locker
+}
+
+func newOSC52Backend(getenv func(string) string, terminal sync.Locker) osc52Backend {
+	return osc52Backend{tmux: getenv("TMUX") != "", open: openTTY, terminal: terminal}
+}
 
+func (osc52Backend) Name() string {
+	return BackendOSC52
+}
+
+func (b osc52Backend) Copy(value string) error {
+	tty, err := b.open()
+	if err != nil {
+		return fmt.Errorf("%v: %v", BackendOSC52, err)
+	}
+	b.terminal.Lock()
+	_, err = io.WriteString(tty, osc52Sequence(value, b.tmux))
+	b.terminal.Unlock()
+	if closeErr := tty.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("%v: %v", BackendOSC52, err)
+	}
+	return nil
+}
+
+// osc52Sequence returns the escape sequence which sets clipboard to value. tmux needs it wrapped in a passthrough
+// sequence with escapes doubled, and allow-passthrough enabled.
+func osc52Sequence(value string, tmux bool) string {
+	sequence := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(value)) + "\a"
+	if tmux {
+		return "\x1bPtmux;" + strings.Replace(sequence, "\x1b", "\x1b\x1b", -1) + "\x1b\\"
+	}
+	return sequence
 }
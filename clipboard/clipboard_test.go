@@ -0,0 +1,121 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestDetectBackends(t *testing.T) {
+	testTable := []struct {
+		env      map[string]string
+		goos     string
+		found    []string
+		expected []string
+	}{
+		{map[string]string{}, "linux", []string{"wl-copy", "xclip", "xsel"}, []string{"wl-copy", "xclip", "xsel", "osc52"}},
+		{map[string]string{}, "linux", []string{"xsel"}, []string{"xsel", "osc52"}},
+		{map[string]string{}, "linux", []string{}, []string{"osc52"}},
+		{map[string]string{"SSH_TTY": "/dev/pts/1"}, "linux", []string{"xclip"}, []string{"xclip", "osc52"}},
+		{map[string]string{"SSH_CONNECTION": "10.0.0.1 51000 10.0.0.2 22"}, "darwin", []string{"pbcopy"}, []string{"osc52", "pbcopy"}},
+		{map[string]string{}, "darwin", []string{"pbcopy", "xclip"}, []string{"pbcopy", "osc52"}},
+	}
+	for index, tc := range testTable {
+		t.Run(fmt.Sprintf("%v %v", index, tc.goos), func(t *testing.T) {
+			getenv := func(key string) string { return tc.env[key] }
+			lookPath := func(name string) (string, error) {
+				for _, found := range tc.found {
+					if found == name {
+						return "/usr/bin/" + name, nil
+					}
+				}
+				return "", errors.New("not found")
+			}
+			names := make([]string, 0)
+			for _, backend := range detectBackends(getenv, tc.goos, lookPath, &terminalLock{}).backends {
+				names = append(names, backend.Name())
+			}
+			if !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf("Invalid backends. Want: %v, Got: %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+func TestCopyFallback(t *testing.T) {
+	commands := make([]string, 0)
+	run := func(input string, name string, args ...string) error {
+		commands = append(commands, fmt.Sprint(name, args, input))
+		if name == BackendWlCopy {
+			return errors.New("wl-copy: no wayland display")
+		}
+		return nil
+	}
+	c := &Clipboard{backends: []Backend{
+		commandBackend{name: BackendWlCopy, run: run},
+		commandBackend{name: BackendXClip, args: []string{"-selection", "clipboard"}, run: run},
+		commandBackend{name: BackendXSel, run: run},
+	}}
+	backend, err := c.Copy("value")
+	if err != nil || backend != BackendXClip {
+		t.Errorf("Invalid backend. Want: %v, Got: %v, %v", BackendXClip, backend, err)
+	}
+	expected := []string{"wl-copy[]value", "xclip[-selection clipboard]value"}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("Invalid commands.\nWant: %q\nGot:  %q", expected, commands)
+	}
+}
+
+// fakeTerminalLock tells whether the terminal is locked.
+type fakeTerminalLock struct {
+	locked bool
+}
+
+func (l *fakeTerminalLock) Lock() {
+	l.locked = true
+}
+
+func (l *fakeTerminalLock) Unlock() {
+	l.locked = false
+}
+
+// closeBuffer is a terminal which fails writes done without the terminal lock.
+type closeBuffer struct {
+	bytes.Buffer
+	terminal *fakeTerminalLock
+}
+
+func (cb *closeBuffer) Write(p []byte) (int, error) {
+	if !cb.terminal.locked {
+		return 0, errors.New("terminal is not locked")
+	}
+	return cb.Buffer.Write(p)
+}
+
+func (*closeBuffer) Close() error {
+	return nil
+}
+
+func TestOSC52(t *testing.T) {
+	testTable := []struct {
+		tmux     bool
+		expected string
+	}{
+		{false, "\x1b]52;c;a3ViZWN0bA==\a"},
+		{true, "\x1bPtmux;\x1b\x1b]52;c;a3ViZWN0bA==\a\x1b\\"},
+	}
+	for _, tc := range testTable {
+		terminal := &fakeTerminalLock{}
+		tty := &closeBuffer{terminal: terminal}
+		backend := osc52Backend{tmux: tc.tmux, open: func() (io.WriteCloser, error) { return tty, nil }, terminal: terminal}
+		if err := backend.Copy("kubectl"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if tty.String() != tc.expected {
+			t.Errorf("Invalid sequence. Want: %q, Got: %q", tc.expected, tty.String())
+		}
+	}
+}
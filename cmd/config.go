@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/JLevconoks/k8ConsoleViewer/app"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+)
+
+// readConfig reads config.json from the app directory, the file is optional.
+func readConfig() (app.Config, error) {
+	config := app.Config{}
+	appDir, err := getAppDir()
+	if err != nil {
+		return config, errors.Wrap(err, "Error reading config")
+	}
+
+	configFilePath := appDir + "/config.json"
+	bytes, err := ioutil.ReadFile(configFilePath)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, errors.Wrap(err, fmt.Sprint("Error reading file: ", configFilePath))
+	}
+
+	if err := json.Unmarshal(bytes, &config); err != nil {
+		return config, errors.Wrap(err, fmt.Sprint("Error unmarshalling file: ", configFilePath))
+	}
+	return config, nil
+}
@@ -41,7 +41,12 @@ func runGroupCmd(cmd *cobra.Command, args []string) {
 		os.Exit(0)
 	}
 
-	k8app, err := app.NewAppFromGroup(group)
+	options, err := appOptions()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(0)
+	}
+	k8app, err := app.NewAppFromGroup(group, options)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(0)
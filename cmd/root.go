@@ -14,10 +14,18 @@ var rootCmd = &cobra.Command{
 }
 
 var (
-	buildVersion = ""
-	buildTime    = ""
-	namespace    string
-	context      string
+	buildVersion   = ""
+	buildTime      = ""
+	namespace      string
+	context        string
+	watch          bool
+	kubeconfig     string
+	selector       string
+	fieldSelector  string
+	podGroupLabels []string
+	groupBy        []string
+	launcher       string
+	readOnly       bool
 )
 
 func Execute() {
@@ -33,15 +41,48 @@ func init() {
 	rootCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "namespace value")
 	rootCmd.MarkFlagRequired("context")
 	rootCmd.MarkFlagRequired("namespace")
+	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "path to the kubeconfig file, defaults to $KUBECONFIG or ~/.kube/config")
+	rootCmd.PersistentFlags().StringVarP(&selector, "selector", "l", "", "label selector to filter pods on, groups.json 'labelSelector' takes precedence")
+	rootCmd.PersistentFlags().StringVar(&fieldSelector, "field-selector", "", "field selector to filter pods on, groups.json 'fieldSelector' takes precedence")
+	rootCmd.PersistentFlags().StringSliceVar(&podGroupLabels, "pod-group-labels", app.DefaultPodGroupLabels, "label keys used to group pods without an owning controller")
+	rootCmd.PersistentFlags().StringArrayVar(&groupBy, "group-by", nil, "pod grouping strategy, repeat in order of precedence: owner, label:<key>, annotation:<key>, regex:<pattern> (default owner followed by --pod-group-labels)")
+	rootCmd.PersistentFlags().StringVar(&launcher, "launcher", "", "terminal launcher for Ctrl shortcuts: auto, iterm2, tmux, kitty, wezterm, generic, config.json 'launcher' is used when not set")
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "disable delete, scale, exec and other shortcuts which change resources")
+	rootCmd.PersistentFlags().BoolVar(&watch, "watch", true, "watch pods through informers, set to false to poll every 5 seconds")
 
 	rootCmd.Version = fmt.Sprintf("%s (%s)", buildVersion, buildTime)
 }
 
 func runRootCmd(cmd *cobra.Command, args []string) {
-	k8App, err := app.NewApp(context, namespace)
+	options, err := appOptions()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	k8App, err := app.NewApp(context, namespace, options)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 	k8App.Run()
 }
+
+func appOptions() (app.Options, error) {
+	config, err := readConfig()
+	if err != nil {
+		return app.Options{}, err
+	}
+	if launcher != "" {
+		config.Launcher = launcher
+	}
+	return app.Options{
+		Watch:          watch,
+		Kubeconfig:     kubeconfig,
+		LabelSelector:  selector,
+		FieldSelector:  fieldSelector,
+		PodGroupLabels: podGroupLabels,
+		GroupBy:        groupBy,
+		ReadOnly:       readOnly,
+		Config:         config,
+	}, nil
+}
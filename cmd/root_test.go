@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupByFlag(t *testing.T) {
+	args := []string{"--group-by", "owner", "--group-by", `regex:^(\w{1,5})-`}
+	if err := rootCmd.PersistentFlags().Parse(args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"owner", `regex:^(\w{1,5})-`}
+	if !reflect.DeepEqual(groupBy, expected) {
+		t.Errorf("Invalid group by.\nWant: %q\nGot:  %q", expected, groupBy)
+	}
+}
@@ -9,5 +9,6 @@ require (
 	k8s.io/api v0.0.0-20190531132109-d3f5f50bdd94
 	k8s.io/apimachinery v0.0.0-20190531131812-859a0ba5e71a
 	k8s.io/client-go v0.0.0-20190531132439-88ff0afc48bb
+	k8s.io/klog v0.3.2
 	k8s.io/kubernetes v1.14.2
 )
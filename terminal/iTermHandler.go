@@ -26,11 +26,11 @@ async def main(connection):
     {{ if eq $index 0 -}}
     left0 = window.current_tab.current_session
     domain.add_session(left0)
-    await left0.async_send_text("{{$command}}\n")
+    await left0.async_send_text({{sendText $command}})
     {{else if eq $index 1 -}}
     right0 = await left0.async_split_pane(vertical=True)
     domain.add_session(right0)
-    await right0.async_send_text("{{$command}}\n")
+    await right0.async_send_text({{sendText $command}})
     {{else -}}
     {{ $leftRight := remainder $index 2 -}}
     {{ $winIndex := div $index 2 -}}
@@ -39,11 +39,11 @@ async def main(connection):
     {{if eq $leftRight 0 -}}
     left{{$winIndex}} = await left{{$parentIndex}}.async_split_pane()
     domain.add_session(left{{$winIndex}})
-    await left{{$winIndex}}.async_send_text("{{$command}}\n")
+    await left{{$winIndex}}.async_send_text({{sendText $command}})
     {{else -}}
     right{{$winIndex}} = await right{{$parentIndex}}.async_split_pane()
     domain.add_session(right{{$winIndex}})
-    await right{{$winIndex}}.async_send_text("{{$command}}\n")
+    await right{{$winIndex}}.async_send_text({{sendText $command}})
     {{end -}}
     {{end -}}
     {{end}}
@@ -51,28 +51,14 @@ async def main(connection):
 
 iterm2.run_until_complete(main)`
 
-func OpenAndExecute(commands []string) error {
-	fm := template.FuncMap{
-		"remainder": func(i, j int) int { return i % j },
-		"div":       func(i, j int) int { return i / j },
-		"minus":     func(i, j int) int { return i - j },
-	}
-	templ := template.New("openIterm.template").Funcs(fm)
-
-	templ, err := templ.Parse(itermTemplate)
-	if err != nil {
-		return err
-	}
-
-	template.Must(templ, err)
-	var b bytes.Buffer
-
-	err = templ.Execute(&b, commands)
+// OpenAndExecute opens an iTerm2 window with a pane for every command, commands are typed into the panes.
+func OpenAndExecute(commands [][]string) error {
+	script, err := itermScript(commands)
 	if err != nil {
 		return err
 	}
 
-	cmd := exec.Command("python3", "-c", b.String())
+	cmd := exec.Command("python3", "-c", script)
 
 	cmd.Stderr = os.Stdout
 	cmd.Stdout = os.Stdout
@@ -84,3 +70,23 @@ func OpenAndExecute(commands []string) error {
 
 	return nil
 }
+
+// itermScript renders the Python script, every command line is a properly escaped Python string literal.
+func itermScript(commands [][]string) (string, error) {
+	fm := template.FuncMap{
+		"remainder": func(i, j int) int { return i % j },
+		"div":       func(i, j int) int { return i / j },
+		"minus":     func(i, j int) int { return i - j },
+		"sendText":  func(argv []string) string { return pythonString(ShellJoin(argv) + "\n") },
+	}
+	templ, err := template.New("openIterm.template").Funcs(fm).Parse(itermTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	if err := templ.Execute(&b, commands); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,226 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	LauncherAuto    = "auto"
+	LauncherITerm2  = "iterm2"
+	LauncherTmux    = "tmux"
+	LauncherKitty   = "kitty"
+	LauncherWezTerm = "wezterm"
+	LauncherGeneric = "generic"
+
+	windowTitle = "k8ConsoleViewer"
+)
+
+// Launcher opens commands in a new terminal window or tab, every command gets its own pane. Commands are argv slices,
+// backends quote them for the shell or script which runs them.
+type Launcher interface {
+	Name() string
+	Launch(commands [][]string) error
+}
+
+// runner runs a program and returns its trimmed standard output, start runs it without waiting. Both are replaced in
+// tests.
+type runner func(name string, args ...string) (string, error)
+type starter func(name string, args ...string) error
+
+func runCommand(name string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%v: %v %v", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func startCommand(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// The terminal is independent of the app, it is waited for only so that it doesn't stay a zombie after it exits.
+	go func() {
+		_ = cmd.Wait()
+	}()
+	return nil
+}
+
+// NewLauncher returns launcher backend by name, empty name or auto detects it from the environment.
+func NewLauncher(name string) (Launcher, error) {
+	switch name {
+	case "", LauncherAuto:
+		return detectLauncher(os.Getenv, runtime.GOOS), nil
+	case LauncherITerm2:
+		return iTermLauncher{}, nil
+	case LauncherTmux:
+		return tmuxLauncher{run: runCommand}, nil
+	case LauncherKitty:
+		return kittyLauncher{run: runCommand}, nil
+	case LauncherWezTerm:
+		return wezTermLauncher{run: runCommand}, nil
+	case LauncherGeneric:
+		return genericLauncher{terminal: os.Getenv("TERMINAL"), start: startCommand}, nil
+	}
+	return nil, fmt.Errorf("unknown launcher '%v', supported launchers: %v", name,
+		strings.Join([]string{LauncherAuto, LauncherITerm2, LauncherTmux, LauncherKitty, LauncherWezTerm, LauncherGeneric}, ", "))
+}
+
+// detectLauncher prefers the terminal the app is running in, iTerm2 is kept as a default on macOS.
+func detectLauncher(getenv func(string) string, goos string) Launcher {
+	switch {
+	case getenv("TMUX") != "":
+		return tmuxLauncher{run: runCommand}
+	case getenv("KITTY_WINDOW_ID") != "":
+		return kittyLauncher{run: runCommand}
+	case getenv("WEZTERM_PANE") != "":
+		return wezTermLauncher{run: runCommand}
+	case getenv("TERM_PROGRAM") == "iTerm.app":
+		return iTermLauncher{}
+	case getenv("TERMINAL") == "" && goos == "darwin":
+		return iTermLauncher{}
+	}
+	return genericLauncher{terminal: getenv("TERMINAL"), start: startCommand}
+}
+
+// holdCommand returns a shell command line which runs argv and keeps the pane open with an interactive shell after
+// it exits, like when it is typed in.
+func holdCommand(argv []string) string {
+	return ShellJoin(argv) + `; exec "${SHELL:-/bin/sh}"`
+}
+
+type iTermLauncher struct{}
+
+func (iTermLauncher) Name() string {
+	return LauncherITerm2
+}
+
+func (iTermLauncher) Launch(commands [][]string) error {
+	return OpenAndExecute(commands)
+}
+
+// tmuxLauncher opens a new window in the current tmux session with tiled panes, input is sent to all of them.
+type tmuxLauncher struct {
+	run runner
+}
+
+func (tmuxLauncher) Name() string {
+	return LauncherTmux
+}
+
+func (t tmuxLauncher) Launch(commands [][]string) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	window, err := t.run("tmux", "new-window", "-P", "-F", "#{window_id}", "-n", windowTitle, holdCommand(commands[0]))
+	if err != nil {
+		return err
+	}
+	for _, command := range commands[1:] {
+		if _, err := t.run("tmux", "split-window", "-t", window, holdCommand(command)); err != nil {
+			return err
+		}
+		// Layout is applied after every split, otherwise the window runs out of space for new panes.
+		if _, err := t.run("tmux", "select-layout", "-t", window, "tiled"); err != nil {
+			return err
+		}
+	}
+	_, err = t.run("tmux", "set-window-option", "-t", window, "synchronize-panes", "on")
+	return err
+}
+
+// kittyLauncher opens a new tab with a window per command through kitty remote control, which has to be enabled with
+// allow_remote_control.
+type kittyLauncher struct {
+	run runner
+}
+
+func (kittyLauncher) Name() string {
+	return LauncherKitty
+}
+
+func (k kittyLauncher) Launch(commands [][]string) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	window, err := k.run("kitty", "@", "launch", "--type=tab", "--tab-title", windowTitle, "sh", "-c", holdCommand(commands[0]))
+	if err != nil {
+		return err
+	}
+	for _, command := range commands[1:] {
+		_, err := k.run("kitty", "@", "launch", "--type=window", "--match", "window_id:"+window, "sh", "-c", holdCommand(command))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wezTermLauncher opens a new tab with a pane per command through wezterm cli. wezterm cli has no tiled layout, so
+// panes are split in turns, alternating right and bottom splits, to keep them about the same size.
+type wezTermLauncher struct {
+	run runner
+}
+
+func (wezTermLauncher) Name() string {
+	return LauncherWezTerm
+}
+
+func (w wezTermLauncher) Launch(commands [][]string) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	pane, err := w.run("wezterm", "cli", "spawn", "--", "sh", "-c", holdCommand(commands[0]))
+	if err != nil {
+		return err
+	}
+	// queue has panes in the order they are split, splits counts splits of every pane.
+	queue := []string{pane}
+	splits := map[string]int{pane: 0}
+	for _, command := range commands[1:] {
+		target := queue[0]
+		direction := "--right"
+		if splits[target]%2 == 1 {
+			direction = "--bottom"
+		}
+		pane, err := w.run("wezterm", "cli", "split-pane", "--pane-id", target, direction, "--", "sh", "-c", holdCommand(command))
+		if err != nil {
+			return err
+		}
+		splits[target]++
+		splits[pane] = splits[target]
+		queue = append(queue[1:], target, pane)
+	}
+	return nil
+}
+
+// genericLauncher opens a window of $TERMINAL per command, most terminal emulators accept -e.
+type genericLauncher struct {
+	terminal string
+	start    starter
+}
+
+func (genericLauncher) Name() string {
+	return LauncherGeneric
+}
+
+func (g genericLauncher) Launch(commands [][]string) error {
+	if g.terminal == "" {
+		return fmt.Errorf("no terminal launcher found, set $TERMINAL or 'launcher' in config.json")
+	}
+	for _, command := range commands {
+		if err := g.start(g.terminal, "-e", "sh", "-c", holdCommand(command)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package terminal
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectLauncher(t *testing.T) {
+	testTable := []struct {
+		env      map[string]string
+		goos     string
+		expected string
+	}{
+		{map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0", "TERM_PROGRAM": "iTerm.app"}, "darwin", LauncherTmux},
+		{map[string]string{"KITTY_WINDOW_ID": "1"}, "linux", LauncherKitty},
+		{map[string]string{"WEZTERM_PANE": "0"}, "linux", LauncherWezTerm},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, "darwin", LauncherITerm2},
+		{map[string]string{}, "darwin", LauncherITerm2},
+		{map[string]string{"TERMINAL": "alacritty"}, "linux", LauncherGeneric},
+		{map[string]string{}, "linux", LauncherGeneric},
+	}
+	for index, tc := range testTable {
+		t.Run(fmt.Sprintf("%v %v", index, tc.expected), func(t *testing.T) {
+			getenv := func(key string) string { return tc.env[key] }
+			if launcher := detectLauncher(getenv, tc.goos); launcher.Name() != tc.expected {
+				t.Errorf("Invalid launcher. Want: %v, Got: %v", tc.expected, launcher.Name())
+			}
+		})
+	}
+}
+
+func TestNewLauncherUnknown(t *testing.T) {
+	if _, err := NewLauncher("konsole"); err == nil {
+		t.Errorf("Expected error for unknown launcher")
+	}
+}
+
+// recorder records commands and returns output for programs which print ids.
+type recorder struct {
+	commands []string
+	output   string
+}
+
+func (r *recorder) run(name string, args ...string) (string, error) {
+	r.commands = append(r.commands, name+" "+strings.Join(args, " "))
+	return r.output, nil
+}
+
+func TestTmuxLauncher(t *testing.T) {
+	r := &recorder{output: "@3"}
+	err := tmuxLauncher{run: r.run}.Launch([][]string{{"kubectl", "logs", "a"}, {"kubectl", "logs", "b"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{
+		`tmux new-window -P -F #{window_id} -n k8ConsoleViewer kubectl logs a; exec "${SHELL:-/bin/sh}"`,
+		`tmux split-window -t @3 kubectl logs b; exec "${SHELL:-/bin/sh}"`,
+		`tmux select-layout -t @3 tiled`,
+		`tmux set-window-option -t @3 synchronize-panes on`,
+	}
+	if !reflect.DeepEqual(r.commands, expected) {
+		t.Errorf("Invalid commands.\nWant: %q\nGot:  %q", expected, r.commands)
+	}
+}
+
+func TestWezTermLauncher(t *testing.T) {
+	commands := make([]string, 0)
+	run := func(name string, args ...string) (string, error) {
+		// Pane ids are printed by wezterm, commands of panes are left out.
+		for index, arg := range args {
+			if arg == "--" {
+				args = args[:index]
+				break
+			}
+		}
+		commands = append(commands, strings.Join(args, " "))
+		return fmt.Sprint(len(commands) - 1), nil
+	}
+	pods := [][]string{{"a"}, {"b"}, {"c"}, {"d"}, {"e"}}
+	if err := (wezTermLauncher{run: run}).Launch(pods); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// First pane is split into halves, both halves into quarters and then the first quarter again.
+	expected := []string{
+		"cli spawn",
+		"cli split-pane --pane-id 0 --right",
+		"cli split-pane --pane-id 0 --bottom",
+		"cli split-pane --pane-id 1 --bottom",
+		"cli split-pane --pane-id 0 --right",
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("Invalid commands.\nWant: %q\nGot:  %q", expected, commands)
+	}
+}
+
+func TestGenericLauncherWithoutTerminal(t *testing.T) {
+	started := false
+	start := func(name string, args ...string) error {
+		started = true
+		return nil
+	}
+	if err := (genericLauncher{start: start}).Launch([][]string{{"kubectl", "logs", "a"}}); err == nil || started {
+		t.Errorf("Expected error without $TERMINAL, Got: %v, started: %v", err, started)
+	}
+}
@@ -0,0 +1,50 @@
+package terminal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// shellSafe matches arguments which mean the same to POSIX shells with and without quotes.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// ShellQuote quotes arg for POSIX shells, so that it is passed as a single argument without any expansion.
+func ShellQuote(arg string) string {
+	if shellSafe.MatchString(arg) {
+		return arg
+	}
+	// Single quotes can't be escaped inside single quotes, so they are closed, escaped and opened again.
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+}
+
+// ShellJoin returns a command line which runs argv in a POSIX shell.
+func ShellJoin(argv []string) string {
+	quoted := make([]string, len(argv))
+	for index, arg := range argv {
+		quoted[index] = ShellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// pythonString returns a Python 3 string literal of value. Quotes, backslashes and all control characters are
+// escaped, so that value can't end the literal or the line.
+func pythonString(value string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for _, r := range value {
+		switch {
+		case r == '"' || r == '\\':
+			builder.WriteByte('\\')
+			builder.WriteRune(r)
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r < 0x20 || r == 0x7f:
+			builder.WriteString(fmt.Sprintf(`\x%02x`, r))
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
@@ -0,0 +1,100 @@
+package terminal
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// hostileArgs are names which break naive quoting, Kubernetes does not allow most of them, but kubeconfig paths,
+// contexts and user defined commands can contain anything.
+var hostileArgs = []string{
+	"web-1",
+	"",
+	"has space",
+	"it's",
+	`say "hi"`,
+	`back\slash`,
+	`trailing\`,
+	"$(touch /tmp/pwned)",
+	"`id`",
+	"a;b&&c|d",
+	"new\nline",
+	"*",
+	"~",
+	"zaļš",
+	`"); import os; os.system("id"); ("`,
+	"\x1b[31m",
+}
+
+func TestShellQuote(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	// Every argument is printed followed by NUL, so that arguments are compared exactly as the shell received them.
+	out, err := exec.Command("sh", "-c", "printf '%s\\0' "+ShellJoin(hostileArgs)).Output()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	args := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	if !reflect.DeepEqual(args, hostileArgs) {
+		t.Errorf("Invalid arguments.\nWant: %q\nGot:  %q", hostileArgs, args)
+	}
+}
+
+func TestShellQuoteSafe(t *testing.T) {
+	if quoted := ShellJoin([]string{"kubectl", "--context", "dev", "-n", "ns", "logs", "web-1", "-c", "app"}); quoted != "kubectl --context dev -n ns logs web-1 -c app" {
+		t.Errorf("Safe arguments should not be quoted, Got: %v", quoted)
+	}
+}
+
+func TestPythonString(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 is not available")
+	}
+	for _, arg := range hostileArgs {
+		out, err := exec.Command("python3", "-c", "import sys; sys.stdout.write("+pythonString(arg)+")").Output()
+		if err != nil {
+			t.Errorf("Invalid literal for %q: %v", arg, err)
+			continue
+		}
+		if string(out) != arg {
+			t.Errorf("Invalid value. Want: %q, Got: %q", arg, out)
+		}
+	}
+}
+
+func TestITermScript(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 is not available")
+	}
+	commands := make([][]string, 0)
+	for _, arg := range hostileArgs[:5] {
+		commands = append(commands, []string{"kubectl", "logs", arg})
+	}
+	script, err := itermScript(commands)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Script is parsed without running it, send_text arguments are the only string constants passed to a call.
+	extract := `import ast, sys
+for node in ast.walk(ast.parse(sys.stdin.read())):
+    if isinstance(node, ast.Call) and getattr(node.func, "attr", "") == "async_send_text":
+        sys.stdout.write(node.args[0].value + "\0")`
+	cmd := exec.Command("python3", "-c", extract)
+	cmd.Stdin = strings.NewReader(script)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Script can't be parsed: %v\n%v", err, script)
+	}
+	texts := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	expected := make([]string, 0)
+	for _, command := range commands {
+		expected = append(expected, ShellJoin(command)+"\n")
+	}
+	if !reflect.DeepEqual(texts, expected) {
+		t.Errorf("Invalid sent text.\nWant: %q\nGot:  %q", expected, texts)
+	}
+}